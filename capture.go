@@ -0,0 +1,81 @@
+package errorwrap
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// timeLayout is the layout of the level timestamps in the formatted output.
+const timeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// captureDisabled is 1 when the capture of the stack traces and timestamps is disabled.
+var captureDisabled int32
+
+// SetCaptureEnabled enables or disables the capture of the stack trace and the creation time of every ErrorWrapper
+// level. Both are enabled by default. Disabling them makes the creation of errors cheaper, e.g. in hot paths where
+// errors are expected and never printed. The levels created meanwhile have an empty StackTrace, no time, and no layer
+// derived from their package.
+func SetCaptureEnabled(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&captureDisabled, 0)
+	} else {
+		atomic.StoreInt32(&captureDisabled, 1)
+	}
+}
+
+// CaptureEnabled checks whether the stack traces and timestamps are captured, see SetCaptureEnabled.
+func CaptureEnabled() bool {
+	return atomic.LoadInt32(&captureDisabled) == 0
+}
+
+func captureTime() time.Time {
+	if !CaptureEnabled() {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// TimeOf returns the time when the current level of err was created. It returns the zero time if err is not an
+// ErrorWrapper or if the capture was disabled.
+func TimeOf(err error) time.Time {
+	if ew, ok := err.(*errorWrapper); ok && ew != nil {
+		return ew.time
+	}
+	return time.Time{}
+}
+
+// Elapsed returns the time elapsed between the creation of the root cause and the creation of the current level of
+// err, e.g. the time spent by retries and timeouts while the error was propagated. It returns false if err is not an
+// ErrorWrapper or if the times were not captured.
+func Elapsed(err error) (time.Duration, bool) {
+	ew, ok := err.(*errorWrapper)
+	if !ok || ew == nil {
+		return 0, false
+	}
+	root := ew.root()
+	if ew.time.IsZero() || root.time.IsZero() {
+		return 0, false
+	}
+	return ew.time.Sub(root.time), true
+}
+
+// root returns the root level of e.
+func (e *errorWrapper) root() *errorWrapper {
+	if rc, ok := e.rootCause.(*errorWrapper); ok && rc != nil {
+		return rc
+	}
+	return e
+}
+
+// timeLine returns the time of e followed by the time elapsed since the root cause, or an empty string if the time
+// was not captured.
+func (e *errorWrapper) timeLine() string {
+	if e.time.IsZero() {
+		return ""
+	}
+	line := e.time.Format(timeLayout)
+	if elapsed, ok := Elapsed(e); ok && e.root() != e {
+		line += " (+" + elapsed.String() + ")"
+	}
+	return line
+}
@@ -0,0 +1,83 @@
+package errorwrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestElapsed(t *testing.T) {
+	root := NewError(ErrorInfraDatabase)
+	time.Sleep(2 * time.Millisecond)
+	err := Wrap(root, ErrorDomain)
+
+	assert.False(t, TimeOf(root).IsZero())
+	assert.True(t, TimeOf(err).After(TimeOf(root)))
+	assert.True(t, TimeOf(ErrorDomain).IsZero())
+
+	elapsed, ok := Elapsed(err)
+	assert.True(t, ok)
+	assert.GreaterOrEqual(t, int64(elapsed), int64(2*time.Millisecond))
+
+	elapsed, ok = Elapsed(root)
+	assert.True(t, ok)
+	assert.Zero(t, elapsed)
+
+	_, ok = Elapsed(ErrorDomain)
+	assert.False(t, ok)
+}
+
+func TestTimeFormat(t *testing.T) {
+	err := Wrap(NewError(ErrorInfraDatabase), ErrorDomain)
+
+	timeLine := `    time: \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}(Z|[+-]\d{2}:\d{2})`
+	assert.Regexp(t, regexp.MustCompile(`^ -  error domain layer\n`+timeLine+` \(\+[^)]+\)\n -  error infra layer\n`+
+		timeLine+`\n\n`), fmt.Sprintf("%+v", err))
+	assert.Equal(t, " -  error domain layer\n -  error infra layer", fmt.Sprintf("%+s", err))
+	assert.NotContains(t, fmt.Sprintf("%+v", Normalized(err, GoldenOptions)), "time: ")
+
+	b, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	var got struct {
+		Levels []struct {
+			Time    *time.Time `json:"time"`
+			Elapsed string     `json:"elapsed"`
+		} `json:"levels"`
+	}
+	assert.NoError(t, json.Unmarshal(b, &got))
+	if assert.Len(t, got.Levels, 2) {
+		assert.True(t, TimeOf(err).Equal(*got.Levels[0].Time))
+		assert.NotEmpty(t, got.Levels[0].Elapsed)
+		assert.NotNil(t, got.Levels[1].Time)
+		assert.Empty(t, got.Levels[1].Elapsed)
+	}
+}
+
+func TestSetCaptureEnabled(t *testing.T) {
+	SetCaptureEnabled(false)
+	defer SetCaptureEnabled(true)
+	assert.False(t, CaptureEnabled())
+
+	err := Wrap(AppendInto(nil, ErrorInfraDatabase), ErrorDomain)
+	ew := err.(ErrorWrapper)
+	assert.Empty(t, ew.StackTrace())
+	assert.Empty(t, ew.RootCause().StackTrace())
+	assert.True(t, TimeOf(err).IsZero())
+	_, ok := Elapsed(err)
+	assert.False(t, ok)
+
+	assert.Equal(t, " -  error domain layer\n -  error infra layer\n\n", fmt.Sprintf("%+v", err))
+	assert.Equal(t, " -  error domain layer\n -  error infra layer\n\n", fmt.Sprintf("%+v", Normalized(err, GoldenOptions)))
+	b, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	assert.False(t, strings.Contains(string(b), `"time"`))
+	assert.Equal(t, "", LayerOf(ew))
+
+	SetCaptureEnabled(true)
+	assert.True(t, CaptureEnabled())
+	assert.NotEmpty(t, NewError(ErrorDomain).(ErrorWrapper).StackTrace())
+}
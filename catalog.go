@@ -0,0 +1,160 @@
+package errorwrap
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Catalog resolves the localized message of an ErrorDefinition code.
+type Catalog interface {
+	// Message returns the message of code in locale. It returns false if there is no message for code.
+	Message(code string, locale string) (string, bool)
+}
+
+// MessageCatalog is an in-memory Catalog. Messages are looked up following the fallback chain of the requested
+// locale:
+//
+//	requested locale -> fallbacks set using SetFallback -> parent locales ("pt-BR" -> "pt") -> default locale
+//
+// Locales are case-insensitive and "_" is treated as "-". It is safe for concurrent use.
+type MessageCatalog struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]string
+	fallbacks     map[string][]string
+}
+
+// NewMessageCatalog creates an empty MessageCatalog.
+func NewMessageCatalog(defaultLocale string) *MessageCatalog {
+	return &MessageCatalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		messages:      map[string]map[string]string{},
+		fallbacks:     map[string][]string{},
+	}
+}
+
+// Add adds messages (code -> message) of locale. Existing codes are overwritten.
+func (c *MessageCatalog) Add(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	locale = normalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]string{}
+	}
+	for code, msg := range messages {
+		c.messages[locale][code] = msg
+	}
+}
+
+// SetFallback sets the locales that are looked up when a message is missing in locale.
+func (c *MessageCatalog) SetFallback(locale string, fallbacks ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	normalized := make([]string, 0, len(fallbacks))
+	for _, fallback := range fallbacks {
+		normalized = append(normalized, normalizeLocale(fallback))
+	}
+	c.fallbacks[normalizeLocale(locale)] = normalized
+}
+
+// LoadJSON adds the messages read from r. The document maps locales to codes to messages:
+//
+//	{"en": {"USER_NOT_FOUND": "user {id} not found"}, "id": {"USER_NOT_FOUND": "pengguna {id} tidak ditemukan"}}
+func (c *MessageCatalog) LoadJSON(r io.Reader) error {
+	var messages map[string]map[string]string
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return err
+	}
+	c.addAll(messages)
+	return nil
+}
+
+// LoadYAML adds the messages read from r. The document has the same layout as the one read by LoadJSON.
+func (c *MessageCatalog) LoadYAML(r io.Reader) error {
+	var messages map[string]map[string]string
+	if err := yaml.NewDecoder(r).Decode(&messages); err != nil {
+		return err
+	}
+	c.addAll(messages)
+	return nil
+}
+
+func (c *MessageCatalog) addAll(messages map[string]map[string]string) {
+	for locale, msgs := range messages {
+		c.Add(locale, msgs)
+	}
+}
+
+func (c *MessageCatalog) Message(code string, locale string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range c.fallbackChain(normalizeLocale(locale)) {
+		if msg, ok := c.messages[l][code]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+func (c *MessageCatalog) fallbackChain(locale string) []string {
+	var chain []string
+	visited := map[string]bool{}
+	var visit func(locale string)
+	visit = func(locale string) {
+		if locale == "" || visited[locale] {
+			return
+		}
+		visited[locale] = true
+		chain = append(chain, locale)
+		for _, fallback := range c.fallbacks[locale] {
+			visit(fallback)
+		}
+		if i := strings.LastIndex(locale, "-"); i > 0 {
+			visit(locale[:i])
+		}
+	}
+	visit(locale)
+	visit(c.defaultLocale)
+	return chain
+}
+
+// LocalizedMessage returns the message of err in locale resolved by catalog. It will find recursively from current
+// level to the root of ErrorWrapper stack and returns the message of the first ErrorDefinition whose code exists in
+// catalog. The placeholders of the message are replaced by the fields of an instantiated Template. If there is no
+// localized message, LocalizedMessage returns PublicMessage(err).
+func LocalizedMessage(err error, catalog Catalog, locale string) string {
+	if catalog != nil {
+		if msg, ok := localizedMessage(err, catalog, locale); ok {
+			return msg
+		}
+	}
+	return PublicMessage(err)
+}
+
+func localizedMessage(err error, catalog Catalog, locale string) (string, bool) {
+	for err != nil {
+		if ew, ok := err.(ErrorWrapper); ok {
+			for _, curErr := range ew.CurrentError() {
+				if msg, ok := localizedMessage(curErr, catalog, locale); ok {
+					return msg, true
+				}
+			}
+		} else if code := Code(err); code != "" {
+			if msg, ok := catalog.Message(code, locale); ok {
+				if f, ok := err.(fielder); ok {
+					msg = renderTemplate(msg, f.Fields(), true)
+				}
+				return msg, true
+			}
+		}
+		err = Unwrap(err)
+	}
+	return "", false
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
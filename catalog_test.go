@@ -0,0 +1,119 @@
+package errorwrap
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var (
+	ErrorCatalogUserNotFound = NewTemplate("user {id} not found", WithCode("USER_NOT_FOUND"), WithPublicMessage("user not found"))
+	ErrorCatalogForbidden    = New("forbidden", WithCode("FORBIDDEN"), WithPublicMessage("access denied"))
+)
+
+const catalogJSON = `{
+	"en": {"USER_NOT_FOUND": "user {id} not found", "FORBIDDEN": "access denied"},
+	"pt": {"USER_NOT_FOUND": "usuário {id} não encontrado"},
+	"id": {"FORBIDDEN": "akses ditolak"}
+}`
+
+const catalogYAML = `
+pt-BR:
+  FORBIDDEN: acesso negado
+jv:
+  USER_NOT_FOUND: pangguna {id} ora ketemu
+`
+
+func newTestCatalog(t *testing.T) *MessageCatalog {
+	catalog := NewMessageCatalog("en")
+	assert.NoError(t, catalog.LoadJSON(strings.NewReader(catalogJSON)))
+	assert.NoError(t, catalog.LoadYAML(strings.NewReader(catalogYAML)))
+	catalog.SetFallback("jv", "id")
+	return catalog
+}
+
+func TestMessageCatalog(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	type args struct {
+		code   string
+		locale string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		want   string
+		wantOk bool
+	}{
+		{name: "exact locale", args: args{code: "FORBIDDEN", locale: "id"}, want: "akses ditolak", wantOk: true},
+		{name: "region locale", args: args{code: "FORBIDDEN", locale: "pt_br"}, want: "acesso negado", wantOk: true},
+		{name: "parent locale", args: args{code: "USER_NOT_FOUND", locale: "pt-BR"}, want: "usuário {id} não encontrado", wantOk: true},
+		{name: "explicit fallback", args: args{code: "FORBIDDEN", locale: "jv"}, want: "akses ditolak", wantOk: true},
+		{name: "default locale", args: args{code: "FORBIDDEN", locale: "fr-FR"}, want: "access denied", wantOk: true},
+		{name: "unknown code", args: args{code: "UNKNOWN", locale: "en"}, want: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := catalog.Message(tt.args.code, tt.args.locale)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMessageCatalogInvalidDocument(t *testing.T) {
+	catalog := NewMessageCatalog("en")
+	assert.Error(t, catalog.LoadJSON(strings.NewReader(`{"en": "invalid"}`)))
+	assert.Error(t, catalog.LoadYAML(strings.NewReader("en: [invalid]")))
+	// CVE-2022-28948: malformed documents must not panic
+	assert.Error(t, catalog.LoadYAML(strings.NewReader("0: [:!00 \xef")))
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	type args struct {
+		err    error
+		locale string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "template definition",
+			args: args{err: Wrap(NewError(ErrorCatalogUserNotFound.With(Fields{"id": 7})), ErrorDomain), locale: "pt-BR"},
+			want: "usuário 7 não encontrado",
+		},
+		{
+			name: "upper level first",
+			args: args{err: Wrap(NewError(ErrorCatalogUserNotFound.With(Fields{"id": 7})), ErrorCatalogForbidden), locale: "id"},
+			want: "akses ditolak",
+		},
+		{
+			name: "fallback to public message",
+			args: args{err: NewError(New("no code", WithPublicMessage("something went wrong"))), locale: "id"},
+			want: "something went wrong",
+		},
+		{
+			name: "fallback to generic message",
+			args: args{err: errors.New("standard error"), locale: "id"},
+			want: GenericPublicMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LocalizedMessage(tt.args.err, catalog, tt.args.locale))
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, "FORBIDDEN", Code(ErrorCatalogForbidden))
+	assert.Equal(t, "USER_NOT_FOUND", Code(ErrorCatalogUserNotFound))
+	assert.Equal(t, "USER_NOT_FOUND", Code(ErrorCatalogUserNotFound.With(nil)))
+	assert.Equal(t, "", Code(ErrorDomain))
+	assert.Equal(t, "", Code(NewError(ErrorCatalogForbidden)))
+}
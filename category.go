@@ -0,0 +1,45 @@
+package errorwrap
+
+// Category groups ErrorDefinitions by the kind of failure. It is used to map errors onto transport specific codes,
+// e.g. HTTP status or process exit codes, without listing every definition.
+type Category string
+
+const (
+	CategoryUnknown          Category = ""
+	CategoryInvalidArgument  Category = "invalid_argument"
+	CategoryNotFound         Category = "not_found"
+	CategoryAlreadyExists    Category = "already_exists"
+	CategoryPermissionDenied Category = "permission_denied"
+	CategoryUnauthenticated  Category = "unauthenticated"
+	CategoryTimeout          Category = "timeout"
+	CategoryCanceled         Category = "canceled"
+	CategoryUnavailable      Category = "unavailable"
+	CategoryInternal         Category = "internal"
+)
+
+// WithCategory sets the Category of an ErrorDefinition.
+func WithCategory(category Category) DefinitionOption {
+	return func(d *errorDefinition) {
+		d.category = category
+	}
+}
+
+// CategoryOf returns the Category of err. It will find recursively from current level to the root of ErrorWrapper
+// stack and returns the Category of the first ErrorDefinition that has one. If there is none, CategoryOf returns
+// CategoryUnknown.
+func CategoryOf(err error) Category {
+	for ; err != nil; err = Unwrap(err) {
+		if ew, ok := err.(ErrorWrapper); ok {
+			for _, curErr := range ew.CurrentError() {
+				if category := CategoryOf(curErr); category != CategoryUnknown {
+					return category
+				}
+			}
+			continue
+		}
+		if def := definitionOf(err); def != nil && def.category != CategoryUnknown {
+			return def.category
+		}
+	}
+	return CategoryUnknown
+}
@@ -0,0 +1,33 @@
+package errorwrap
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorCategoryInvalid = New("error invalid", WithCategory(CategoryInvalidArgument))
+)
+
+func TestCategoryOf(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want Category
+	}{
+		{name: "nil error", args: args{err: nil}, want: CategoryUnknown},
+		{name: "without category", args: args{err: appLayer()}, want: CategoryUnknown},
+		{name: "definition", args: args{err: ErrorCategoryInvalid}, want: CategoryInvalidArgument},
+		{name: "root level", args: args{err: Wrap(NewError(ErrorCommonNotFound), ErrorDomain)}, want: CategoryNotFound},
+		{name: "upper level first", args: args{err: Wrap(NewError(ErrorCommonNotFound), ErrorCategoryInvalid)}, want: CategoryInvalidArgument},
+		{name: "template", args: args{err: NewError(NewTemplate("{x}", WithCategory(CategoryTimeout)).With(nil))}, want: CategoryTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CategoryOf(tt.args.err))
+		})
+	}
+}
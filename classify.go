@@ -0,0 +1,134 @@
+package errorwrap
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// Well-known ErrorDefinitions. They are the targets of StdClassifier.
+var (
+	ErrorCommonNotFound         = New("error not found", WithCode("NOT_FOUND"), WithCategory(CategoryNotFound))
+	ErrorCommonPermissionDenied = New("error permission denied", WithCode("PERMISSION_DENIED"),
+		WithCategory(CategoryPermissionDenied), Permanent())
+	ErrorCommonTimeout     = New("error timeout", WithCode("TIMEOUT"), WithCategory(CategoryTimeout), Retryable())
+	ErrorCommonCanceled    = New("error canceled", WithCode("CANCELED"), WithCategory(CategoryCanceled), Permanent())
+	ErrorCommonUnavailable = New("error unavailable", WithCode("UNAVAILABLE"), WithCategory(CategoryUnavailable),
+		Retryable())
+)
+
+// Classifier maps a foreign error, i.e. an error that is neither an ErrorDefinition nor an ErrorWrapper, onto an
+// ErrorDefinition. It returns nil if err is not recognized.
+type Classifier func(err error) error
+
+var (
+	classifierMu sync.RWMutex
+	classifiers  []Classifier
+)
+
+// RegisterClassifier registers classifiers. The registry is empty by default. Once registered, the classifiers are
+// applied to every foreign error placed in a level by NewError, NewErrorWithMessage, AppendInto, Wrap and
+// WrapWithMessage, and the matched ErrorDefinitions are added into the same level.
+//
+//	errorwrap.RegisterClassifier(errorwrap.StdClassifier)
+//	err := errorwrap.Wrap(sql.ErrNoRows, ErrorDomain)
+//	errorwrap.Is(err, errorwrap.ErrorCommonNotFound) // true
+func RegisterClassifier(classifier ...Classifier) {
+	classifierMu.Lock()
+	defer classifierMu.Unlock()
+	for _, c := range classifier {
+		if c == nil {
+			continue
+		}
+		classifiers = append(classifiers, c)
+	}
+}
+
+// Classify applies the registered classifiers to err. If err is a foreign error, Classify returns a base or root
+// ErrorWrapper containing err and the matched ErrorDefinitions. If err is an ErrorWrapper, the levels created before
+// the classifiers were registered are classified in place. If err is nil then Classify returns nil.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	ew, ok := err.(*errorWrapper)
+	if !ok || ew == nil {
+		if definitionOf(err) != nil {
+			return err
+		}
+		ew = newRootWrapper(callStack(), err)
+		if ew == nil {
+			return nil
+		}
+		return ew
+	}
+	for level := ew; level != nil; {
+		level.errors = classifyErrors(level.errors)
+		level, _ = level.parentError.(*errorWrapper)
+	}
+	return ew
+}
+
+// StdClassifier classifies errors of the standard library:
+//
+//	sql.ErrNoRows, os.ErrNotExist                              ErrorCommonNotFound
+//	os.ErrPermission                                           ErrorCommonPermissionDenied
+//	context.DeadlineExceeded, net.Error with Timeout() == true ErrorCommonTimeout
+//	context.Canceled                                           ErrorCommonCanceled
+//	syscall.ECONNREFUSED                                       ErrorCommonUnavailable
+func StdClassifier(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, os.ErrNotExist):
+		return ErrorCommonNotFound
+	case errors.Is(err, os.ErrPermission):
+		return ErrorCommonPermissionDenied
+	case errors.Is(err, context.Canceled):
+		return ErrorCommonCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ErrorCommonTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorCommonUnavailable
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorCommonTimeout
+	}
+	return nil
+}
+
+// classifyErrors returns errs with the ErrorDefinitions matched by the registered classifiers appended.
+func classifyErrors(errs []error) []error {
+	classifierMu.RLock()
+	defer classifierMu.RUnlock()
+	if len(classifiers) == 0 {
+		return errs
+	}
+
+	classified := errs
+	for _, err := range errs {
+		if _, ok := err.(ErrorWrapper); ok || err == nil || definitionOf(err) != nil {
+			continue
+		}
+		for _, classifier := range classifiers {
+			def := classifier(err)
+			if def == nil || containsError(classified, def) {
+				continue
+			}
+			classified = append(classified, def)
+		}
+	}
+	return classified
+}
+
+func containsError(errs []error, target error) bool {
+	for _, err := range errs {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package errorwrap
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+type testTimeoutError struct{}
+
+func (e *testTimeoutError) Error() string   { return "i/o timeout" }
+func (e *testTimeoutError) Timeout() bool   { return true }
+func (e *testTimeoutError) Temporary() bool { return true }
+
+func setClassifiers(t *testing.T, c ...Classifier) {
+	classifierMu.Lock()
+	old := classifiers
+	classifiers = nil
+	classifierMu.Unlock()
+	RegisterClassifier(c...)
+	t.Cleanup(func() {
+		classifierMu.Lock()
+		classifiers = old
+		classifierMu.Unlock()
+	})
+}
+
+func TestStdClassifier(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want error
+	}{
+		{name: "sql no rows", args: args{err: sql.ErrNoRows}, want: ErrorCommonNotFound},
+		{name: "file not exist", args: args{err: &os.PathError{Op: "open", Path: "/x", Err: os.ErrNotExist}}, want: ErrorCommonNotFound},
+		{name: "permission", args: args{err: os.ErrPermission}, want: ErrorCommonPermissionDenied},
+		{name: "deadline", args: args{err: fmt.Errorf("query: %w", context.DeadlineExceeded)}, want: ErrorCommonTimeout},
+		{name: "canceled", args: args{err: context.Canceled}, want: ErrorCommonCanceled},
+		{name: "net timeout", args: args{err: &net.OpError{Op: "read", Net: "tcp", Err: &testTimeoutError{}}}, want: ErrorCommonTimeout},
+		{name: "connection refused", args: args{err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, want: ErrorCommonUnavailable},
+		{name: "unknown", args: args{err: errors.New("standard error")}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StdClassifier(tt.args.err))
+		})
+	}
+}
+
+func TestClassifierRegistry(t *testing.T) {
+	assert.False(t, Is(Wrap(sql.ErrNoRows, ErrorDomain), ErrorCommonNotFound))
+
+	setClassifiers(t, StdClassifier, nil)
+
+	err := Wrap(sql.ErrNoRows, ErrorDomain)
+	assert.True(t, Is(err, ErrorCommonNotFound))
+	assert.False(t, IsExact(err, ErrorCommonNotFound))
+	assert.True(t, IsExact(err.(ErrorWrapper).ParentError(), ErrorCommonNotFound))
+	assert.Equal(t, CategoryNotFound, CategoryOf(err))
+
+	err = NewError(ErrorInfraDatabase, context.DeadlineExceeded, ErrorCommonTimeout)
+	assert.Len(t, err.(ErrorWrapper).CurrentError(), 3)
+	assert.True(t, IsRetryable(err))
+
+	err = AppendInto(NewError(ErrorInfraDatabase), context.Canceled)
+	assert.True(t, IsExact(err, ErrorCommonCanceled))
+	assert.True(t, IsPermanent(err))
+
+	assert.Len(t, NewError(errors.New("standard error")).(ErrorWrapper).CurrentError(), 1)
+}
+
+func TestClassify(t *testing.T) {
+	assert.Nil(t, Classify(nil))
+	assert.Equal(t, ErrorDomain, Classify(ErrorDomain))
+
+	unclassified := Wrap(sql.ErrNoRows, ErrorDomain)
+	foreign := Classify(sql.ErrNoRows)
+	assert.False(t, Is(foreign, ErrorCommonNotFound))
+
+	setClassifiers(t, StdClassifier)
+
+	foreign = Classify(sql.ErrNoRows)
+	assert.True(t, IsExact(foreign, sql.ErrNoRows))
+	assert.True(t, IsExact(foreign, ErrorCommonNotFound))
+
+	assert.False(t, Is(unclassified, ErrorCommonNotFound))
+	assert.Equal(t, unclassified, Classify(unclassified))
+	assert.True(t, Is(unclassified, ErrorCommonNotFound))
+	assert.Len(t, Classify(unclassified).(ErrorWrapper).ParentError().CurrentError(), 2)
+}
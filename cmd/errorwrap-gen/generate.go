@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"go/format"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var funcs = template.FuncMap{
+	"quote": strconv.Quote,
+	"options": func(d Definition) string {
+		var opts []string
+		opts = append(opts, fmt.Sprintf("errorwrap.WithCode(%q)", d.Code))
+		if d.Category != "" {
+			opts = append(opts, "errorwrap.WithCategory(errorwrap."+d.CategoryName()+")")
+		}
+		if d.PublicMessage != "" {
+			opts = append(opts, fmt.Sprintf("errorwrap.WithPublicMessage(%q)", d.PublicMessage))
+		}
+		switch {
+		case d.retryAfter > 0:
+			opts = append(opts, fmt.Sprintf("errorwrap.WithRetryAfter(%d * time.Millisecond)", d.retryAfter.Milliseconds()))
+		case d.Retry == "retryable":
+			opts = append(opts, "errorwrap.Retryable()")
+		case d.Retry == "permanent":
+			opts = append(opts, "errorwrap.Permanent()")
+		}
+		if d.Sensitive {
+			opts = append(opts, "errorwrap.Sensitive()")
+		}
+		return strings.Join(opts, ", ")
+	},
+	"comment": func(s string) string {
+		return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n// ")
+	},
+	"cell": func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+	},
+	"statusText": http.StatusText,
+}
+
+var codeTemplate = template.Must(template.New("code").Funcs(funcs).Parse(`// Code generated by errorwrap-gen. DO NOT EDIT.
+
+package {{ .Spec.Package }}
+
+import (
+	"github.com/anantadwi13/errorwrap"
+{{- if .HasGRPC }}
+	"google.golang.org/grpc/codes"
+{{- end }}
+{{- if .HasRetryAfter }}
+	"time"
+{{- end }}
+)
+
+var (
+{{- range .Spec.Errors }}
+	// {{ .Name }} is the definition of {{ .Code }}.{{ if .Description }} {{ comment .Description }}{{ end }}
+	{{ .Name }} = errorwrap.{{ if .IsTemplate }}NewTemplate{{ else }}New{{ end }}({{ quote .Message }}, {{ options . }})
+{{- end }}
+)
+
+// Definitions contains every definition of the catalog.
+var Definitions = []error{
+{{- range .Spec.Errors }}
+	{{ .Name }},
+{{- end }}
+}
+{{ if .HasHTTP }}
+// HTTPStatus maps the definitions onto HTTP status codes.
+var HTTPStatus = map[error]int{
+{{- range .Spec.Errors }}{{ if .HTTPStatus }}
+	{{ .Name }}: {{ .HTTPStatus }},
+{{- end }}{{ end }}
+}
+{{ end }}
+{{- if .HasGRPC }}
+// GRPCCode maps the definitions onto gRPC codes.
+var GRPCCode = map[error]codes.Code{
+{{- range .Spec.Errors }}{{ if .GRPCCode }}
+	{{ .Name }}: codes.{{ .GRPCCode }},
+{{- end }}{{ end }}
+}
+{{ end }}
+// Catalog contains the translations of the public messages of the definitions.
+var Catalog = errorwrap.NewMessageCatalog({{ quote .Spec.DefaultLocale }})
+
+func init() {
+	errorwrap.RegisterDefinition(Definitions...)
+{{- range $locale, $messages := .Translations }}
+	Catalog.Add({{ quote $locale }}, map[string]string{
+	{{- range $messages }}
+		{{ quote .Code }}: {{ quote .Message }},
+	{{- end }}
+	})
+{{- end }}
+}
+`))
+
+var docTemplate = template.Must(template.New("doc").Funcs(funcs).Parse(`# Error reference
+
+| Code | Message | Category | HTTP status | gRPC code | Retry |
+| ---- | ------- | -------- | ----------- | --------- | ----- |
+{{- range .Spec.Errors }}
+| ` + "`{{ .Code }}`" + ` | {{ cell (or .PublicMessage "-") }} | {{ or .Category "-" }} | {{ if .HTTPStatus }}{{ .HTTPStatus }} {{ statusText .HTTPStatus }}{{ else }}-{{ end }} | {{ or .GRPCCode "-" }} | {{ or .Retry "-" }}{{ if .RetryAfter }} (after {{ .RetryAfter }}){{ end }} |
+{{- end }}
+{{ range .Spec.Errors }}
+## {{ .Code }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}
+{{- if .PublicMessage }}
+Message: {{ .PublicMessage }}
+{{ end }}
+{{- if .Translations }}
+| Locale | Message |
+| ------ | ------- |
+{{- range $locale, $message := .Translations }}
+| {{ $locale }} | {{ cell $message }} |
+{{- end }}
+{{ end }}
+{{- end }}`))
+
+type translation struct {
+	Code    string
+	Message string
+}
+
+type templateData struct {
+	Spec          *Spec
+	HasHTTP       bool
+	HasGRPC       bool
+	HasRetryAfter bool
+	Translations  map[string][]translation
+}
+
+func newTemplateData(spec *Spec) templateData {
+	data := templateData{Spec: spec, Translations: map[string][]translation{}}
+	for _, d := range spec.Errors {
+		data.HasHTTP = data.HasHTTP || d.HTTPStatus != 0
+		data.HasGRPC = data.HasGRPC || d.GRPCCode != ""
+		data.HasRetryAfter = data.HasRetryAfter || d.retryAfter > 0
+		for locale, msg := range d.Translations {
+			data.Translations[locale] = append(data.Translations[locale], translation{Code: d.Code, Message: msg})
+		}
+	}
+	for _, translations := range data.Translations {
+		sort.Slice(translations, func(i, j int) bool {
+			return translations[i].Code < translations[j].Code
+		})
+	}
+	return data
+}
+
+// GenerateCode generates the Go source of spec.
+func GenerateCode(spec *Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, newTemplateData(spec)); err != nil {
+		return nil, errorwrap.Wrap(err, ErrorGenerate)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errorwrap.WrapWithMessage(err, "format generated code", ErrorGenerate)
+	}
+	return src, nil
+}
+
+// GenerateDoc generates the markdown reference of spec.
+func GenerateDoc(spec *Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := docTemplate.Execute(&buf, newTemplateData(spec)); err != nil {
+		return nil, errorwrap.Wrap(err, ErrorGenerate)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"github.com/anantadwi13/errorwrap"
+	"github.com/anantadwi13/errorwrap/errorwraptest"
+	"github.com/stretchr/testify/assert"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parseTestSpec(t *testing.T, name string) *Spec {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	spec, err := ParseSpec(f, name)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	return spec
+}
+
+func TestGenerateCode(t *testing.T) {
+	src, err := GenerateCode(parseTestSpec(t, "testdata/errors.yaml"))
+	assert.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "errors_gen.go", src, 0)
+	assert.NoError(t, err)
+	errorwraptest.AssertGolden(t, "testdata/errors_gen.go.golden", string(src))
+
+	src, err = GenerateCode(parseTestSpec(t, "testdata/errors.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), `ErrorInternal = errorwrap.New("internal error", errorwrap.WithCode("INTERNAL"), errorwrap.Retryable())`)
+	assert.NotContains(t, string(src), "HTTPStatus")
+	assert.NotContains(t, string(src), "grpc")
+}
+
+func TestGenerateDoc(t *testing.T) {
+	md, err := GenerateDoc(parseTestSpec(t, "testdata/errors.yaml"))
+	assert.NoError(t, err)
+	errorwraptest.AssertGolden(t, "testdata/errors.md.golden", string(md))
+}
+
+func TestParseSpec(t *testing.T) {
+	type args struct {
+		spec string
+		name string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "malformed document",
+			args:    args{spec: "errors: {", name: "errors.yaml"},
+			wantErr: ErrorReadSpec,
+		},
+		{
+			name:    "malformed json",
+			args:    args{spec: "errors: []", name: "errors.json"},
+			wantErr: ErrorReadSpec,
+		},
+		{
+			name:    "invalid code",
+			args:    args{spec: "errors: [{code: '1 2', message: m}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: `code "1 2" is not valid`,
+		},
+		{
+			name:    "duplicated code",
+			args:    args{spec: "errors: [{code: A, message: m}, {code: A, message: m}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: `code "A" is duplicated`,
+		},
+		{
+			name:    "duplicated name",
+			args:    args{spec: "errors: [{code: A_B, message: m}, {code: a-b, message: m}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: `name "ErrorAB" is duplicated`,
+		},
+		{
+			name:    "missing message",
+			args:    args{spec: "errors: [{code: A}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: "message is required",
+		},
+		{
+			name:    "unknown category",
+			args:    args{spec: "errors: [{code: A, message: m, category: other}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: `unknown category "other"`,
+		},
+		{
+			name:    "unknown grpc code",
+			args:    args{spec: "errors: [{code: A, message: m, grpc_code: NotExist}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: `unknown gRPC code "NotExist"`,
+		},
+		{
+			name:    "invalid http status",
+			args:    args{spec: "errors: [{code: A, message: m, http_status: 99}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: "HTTP status 99 is not valid",
+		},
+		{
+			name:    "permanent with retry after",
+			args:    args{spec: "errors: [{code: A, message: m, retry: permanent, retry_after: 1s}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: "a permanent error cannot have retry_after",
+		},
+		{
+			name:    "invalid retry",
+			args:    args{spec: "errors: [{code: A, message: m, retry: sometimes}]", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: `retry "sometimes" must be either retryable or permanent`,
+		},
+		{
+			name:    "invalid package",
+			args:    args{spec: "package: my-errors", name: "errors.yaml"},
+			wantErr: ErrorInvalidSpec,
+			wantMsg: `package "my-errors" is not a valid identifier`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSpec(strings.NewReader(tt.args.spec), tt.args.name)
+			errorwraptest.AssertIsAt(t, err, tt.wantErr, 0)
+			assert.Contains(t, errorwrap.Unwrap(err).Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestNameOf(t *testing.T) {
+	assert.Equal(t, "ErrorUserNotFound", nameOf("USER_NOT_FOUND"))
+	assert.Equal(t, "ErrorAuthTokenExpired", nameOf("auth.token-expired"))
+}
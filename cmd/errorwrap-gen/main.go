@@ -0,0 +1,82 @@
+// Command errorwrap-gen generates errorwrap ErrorDefinitions from a YAML or JSON catalog.
+//
+//	errorwrap-gen -spec errors.yaml -out errors_gen.go -doc ERRORS.md
+//
+// The catalog looks like:
+//
+//	package: apperrors
+//	default_locale: en
+//	errors:
+//	  - code: USER_NOT_FOUND
+//	    message: user {id} not found
+//	    description: The requested user does not exist.
+//	    public_message: user not found
+//	    category: not_found
+//	    http_status: 404
+//	    grpc_code: NotFound
+//	    translations:
+//	      id: pengguna tidak ditemukan
+//	  - code: DATABASE_UNAVAILABLE
+//	    message: database unavailable
+//	    category: unavailable
+//	    retry_after: 5s
+//
+// The generated file contains one variable per definition, the registration of the definitions using
+// errorwrap.RegisterDefinition, a message catalog with the translations, and the HTTP status and gRPC code tables.
+package main
+
+import (
+	"flag"
+	"github.com/anantadwi13/errorwrap"
+	"go/token"
+	"os"
+)
+
+func main() {
+	errorwrap.Main(run)
+}
+
+func run() error {
+	specFile := flag.String("spec", "errors.yaml", "path of the YAML or JSON catalog")
+	out := flag.String("out", "errors_gen.go", "path of the generated Go file")
+	doc := flag.String("doc", "", "path of the generated markdown reference, skipped if empty")
+	pkg := flag.String("package", "", "package name of the generated Go file, overrides the catalog")
+	flag.Parse()
+
+	f, err := os.Open(*specFile)
+	if err != nil {
+		return errorwrap.Wrap(err, ErrorReadSpec)
+	}
+	defer f.Close()
+
+	spec, err := ParseSpec(f, *specFile)
+	if err != nil {
+		return err
+	}
+	if *pkg != "" {
+		if !token.IsIdentifier(*pkg) {
+			return errorwrap.NewErrorWithMessage("invalid package name "+*pkg, ErrorInvalidSpec)
+		}
+		spec.Package = *pkg
+	}
+
+	src, err := GenerateCode(spec)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		return errorwrap.Wrap(err, ErrorGenerate)
+	}
+
+	if *doc == "" {
+		return nil
+	}
+	md, err := GenerateDoc(spec)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*doc, md, 0o644); err != nil {
+		return errorwrap.Wrap(err, ErrorGenerate)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"go/token"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrorInvalidSpec = errorwrap.New("invalid catalog spec", errorwrap.WithCategory(errorwrap.CategoryInvalidArgument))
+	ErrorReadSpec    = errorwrap.New("unable to read catalog spec")
+	ErrorGenerate    = errorwrap.New("unable to generate code")
+)
+
+var (
+	categories = map[errorwrap.Category]string{
+		errorwrap.CategoryInvalidArgument:  "CategoryInvalidArgument",
+		errorwrap.CategoryNotFound:         "CategoryNotFound",
+		errorwrap.CategoryAlreadyExists:    "CategoryAlreadyExists",
+		errorwrap.CategoryPermissionDenied: "CategoryPermissionDenied",
+		errorwrap.CategoryUnauthenticated:  "CategoryUnauthenticated",
+		errorwrap.CategoryTimeout:          "CategoryTimeout",
+		errorwrap.CategoryCanceled:         "CategoryCanceled",
+		errorwrap.CategoryUnavailable:      "CategoryUnavailable",
+		errorwrap.CategoryInternal:         "CategoryInternal",
+	}
+	grpcCodes = map[string]bool{
+		"OK": true, "Canceled": true, "Unknown": true, "InvalidArgument": true, "DeadlineExceeded": true,
+		"NotFound": true, "AlreadyExists": true, "PermissionDenied": true, "ResourceExhausted": true,
+		"FailedPrecondition": true, "Aborted": true, "OutOfRange": true, "Unimplemented": true, "Internal": true,
+		"Unavailable": true, "DataLoss": true, "Unauthenticated": true,
+	}
+	codePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.\-]*$`)
+)
+
+// Spec is a catalog of error definitions.
+type Spec struct {
+	// Package is the package name of the generated code.
+	Package string `json:"package" yaml:"package"`
+	// DefaultLocale is the default locale of the generated message catalog.
+	DefaultLocale string       `json:"default_locale" yaml:"default_locale"`
+	Errors        []Definition `json:"errors" yaml:"errors"`
+}
+
+// Definition is an error definition of a Spec.
+type Definition struct {
+	// Name is the name of the generated variable. It is derived from Code if it is empty, e.g. USER_NOT_FOUND becomes
+	// ErrorUserNotFound.
+	Name    string `json:"name" yaml:"name"`
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+	// Description documents the definition in the generated code and the markdown reference.
+	Description   string `json:"description" yaml:"description"`
+	PublicMessage string `json:"public_message" yaml:"public_message"`
+	Category      string `json:"category" yaml:"category"`
+	HTTPStatus    int    `json:"http_status" yaml:"http_status"`
+	// GRPCCode is the name of a google.golang.org/grpc/codes constant, e.g. NotFound.
+	GRPCCode string `json:"grpc_code" yaml:"grpc_code"`
+	// Retry is either "retryable" or "permanent".
+	Retry string `json:"retry" yaml:"retry"`
+	// RetryAfter is a time.Duration string, e.g. "5s". It implies "retryable".
+	RetryAfter string `json:"retry_after" yaml:"retry_after"`
+	Sensitive  bool   `json:"sensitive" yaml:"sensitive"`
+	// Translations maps locales onto localized public messages.
+	Translations map[string]string `json:"translations" yaml:"translations"`
+
+	retryAfter time.Duration
+}
+
+// IsTemplate reports whether the message has placeholders.
+func (d Definition) IsTemplate() bool {
+	return strings.Contains(d.Message, "{")
+}
+
+// CategoryName returns the name of the errorwrap Category constant of the definition.
+func (d Definition) CategoryName() string {
+	return categories[errorwrap.Category(d.Category)]
+}
+
+// ParseSpec reads a Spec from r. The format is taken from the extension of name: ".json" is decoded as JSON, anything
+// else as YAML.
+func ParseSpec(r io.Reader, name string) (*Spec, error) {
+	spec := &Spec{}
+	var err error
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err = json.NewDecoder(r).Decode(spec)
+	} else {
+		err = yaml.NewDecoder(r).Decode(spec)
+	}
+	if err != nil {
+		return nil, errorwrap.WrapWithMessage(err, name, ErrorReadSpec)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, errorwrap.WrapWithMessage(err, name, ErrorInvalidSpec)
+	}
+	return spec, nil
+}
+
+func (s *Spec) validate() error {
+	if s.Package == "" {
+		s.Package = "errors"
+	}
+	if !token.IsIdentifier(s.Package) {
+		return fmt.Errorf("package %q is not a valid identifier", s.Package)
+	}
+	if s.DefaultLocale == "" {
+		s.DefaultLocale = "en"
+	}
+
+	names := map[string]bool{}
+	codes := map[string]bool{}
+	for i := range s.Errors {
+		d := &s.Errors[i]
+		if !codePattern.MatchString(d.Code) {
+			return fmt.Errorf("errors[%d]: code %q is not valid", i, d.Code)
+		}
+		if codes[d.Code] {
+			return fmt.Errorf("errors[%d]: code %q is duplicated", i, d.Code)
+		}
+		codes[d.Code] = true
+
+		if d.Name == "" {
+			d.Name = nameOf(d.Code)
+		}
+		if !token.IsIdentifier(d.Name) || !token.IsExported(d.Name) {
+			return fmt.Errorf("%s: name %q is not an exported identifier", d.Code, d.Name)
+		}
+		if names[d.Name] {
+			return fmt.Errorf("%s: name %q is duplicated", d.Code, d.Name)
+		}
+		names[d.Name] = true
+
+		if d.Message == "" {
+			return fmt.Errorf("%s: message is required", d.Code)
+		}
+		if d.Category != "" && d.CategoryName() == "" {
+			return fmt.Errorf("%s: unknown category %q", d.Code, d.Category)
+		}
+		if d.GRPCCode != "" && !grpcCodes[d.GRPCCode] {
+			return fmt.Errorf("%s: unknown gRPC code %q", d.Code, d.GRPCCode)
+		}
+		if d.HTTPStatus != 0 && (d.HTTPStatus < 100 || d.HTTPStatus > 599) {
+			return fmt.Errorf("%s: HTTP status %d is not valid", d.Code, d.HTTPStatus)
+		}
+		if d.RetryAfter != "" {
+			retryAfter, err := time.ParseDuration(d.RetryAfter)
+			if err != nil || retryAfter <= 0 {
+				return fmt.Errorf("%s: retry_after %q is not a positive duration", d.Code, d.RetryAfter)
+			}
+			d.retryAfter = retryAfter
+			if d.Retry == "" {
+				d.Retry = "retryable"
+			}
+		}
+		switch d.Retry {
+		case "", "retryable":
+		case "permanent":
+			if d.RetryAfter != "" {
+				return fmt.Errorf("%s: a permanent error cannot have retry_after", d.Code)
+			}
+		default:
+			return fmt.Errorf("%s: retry %q must be either retryable or permanent", d.Code, d.Retry)
+		}
+	}
+	return nil
+}
+
+// nameOf derives a variable name from code, e.g. USER_NOT_FOUND becomes ErrorUserNotFound.
+func nameOf(code string) string {
+	name := "Error"
+	for _, part := range strings.FieldsFunc(code, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	}) {
+		name += strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+	}
+	return name
+}
@@ -0,0 +1,65 @@
+// Command errorwrap-parse reads logs containing errors printed with fmt.Printf("%+v", err) and writes every error
+// dump found as a JSON object, one per line.
+//
+//	errorwrap-parse app.log > errors.jsonl
+//	kubectl logs app | errorwrap-parse -indent
+//
+// The input is read from the files given as arguments, or from the standard input if there is none.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/anantadwi13/errorwrap/logparse"
+	"io"
+	"os"
+)
+
+var (
+	ErrorReadInput   = errorwrap.New("unable to read input")
+	ErrorWriteOutput = errorwrap.New("unable to write output")
+)
+
+func main() {
+	errorwrap.Main(run)
+}
+
+func run() error {
+	indent := flag.Bool("indent", false, "indent the JSON output")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		return parse(os.Stdin, "stdin", os.Stdout, *indent)
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return errorwrap.WrapWithMessage(err, name, ErrorReadInput)
+		}
+		err = parse(f, name, os.Stdout, *indent)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parse(r io.Reader, name string, w io.Writer, indent bool) error {
+	errs, err := logparse.ParseAll(r)
+	if err != nil {
+		return errorwrap.WrapWithMessage(err, name, ErrorReadInput)
+	}
+
+	enc := json.NewEncoder(w)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	for _, e := range errs {
+		if err := enc.Encode(e); err != nil {
+			return errorwrap.Wrap(err, ErrorWriteOutput)
+		}
+	}
+	return nil
+}
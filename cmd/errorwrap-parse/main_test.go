@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := "request failed\n" +
+		" -  error usecase layer\n" +
+		" -  error infra layer\n" +
+		"    context: find user\n" +
+		"\n" +
+		"main.main\n" +
+		"\t/app/main.go:12\n" +
+		"done\n" +
+		" -  error timeout\n"
+
+	out := &bytes.Buffer{}
+	err := parse(strings.NewReader(input), "test", out, false)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"levels":[{"errors":["error usecase layer"]},{"errors":["error infra layer"],"context":"find user"}],`+
+		`"stack":[{"function":"main.main","file":"/app/main.go","line":12}]}`+"\n"+
+		`{"levels":[{"errors":["error timeout"]}]}`+"\n", out.String())
+}
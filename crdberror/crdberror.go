@@ -0,0 +1,177 @@
+// Package crdberror encodes errorwrap errors into the network-portable format of github.com/cockroachdb/errors and
+// decodes them back.
+//
+// Importing the package registers the encoders and decoders of the errorwrap types, so errorwrap chains, including
+// chains mixed with cockroachdb errors, pass through errors.EncodeError and errors.DecodeError:
+//
+//	import _ "github.com/anantadwi13/errorwrap/crdberror"
+//
+//	enc := errors.EncodeError(ctx, err)
+//	...
+//	err := errors.DecodeError(ctx, enc)
+//	errorwrap.Is(err, ErrorUserNotFound) // true
+//
+// Each level is encoded as a cockroachdb wrapper whose cause is the level below, or the first error of CurrentError
+// for the root level. The other errors of the level are encoded by their code if they are definitions registered using
+// errorwrap.RegisterDefinition, otherwise by their message. The context message and the fields of the level are
+// encoded too, the fields values as strings. Like the JSON encoding, the context messages and the fields are redacted.
+// Stack traces are not encoded, the decoded levels capture a new one.
+package crdberror
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/errbase"
+	"github.com/cockroachdb/errors/errorspb"
+	"github.com/gogo/protobuf/proto"
+	"sort"
+	"strings"
+)
+
+// Kinds of level, stored in the safe details of an encoded level.
+const (
+	kindRoot    = "root"
+	kindWrapper = "wrapper"
+)
+
+// Prefixes of the payload entries of an encoded level.
+const (
+	prefixCode    = "code:"
+	prefixMessage = "msg:"
+	prefixContext = "context:"
+	prefixField   = "field:"
+)
+
+var (
+	wrapperKey    = errors.GetTypeKey(errorwrap.NewError(errorwrap.New("")))
+	definitionKey = errors.GetTypeKey(errorwrap.New(""))
+)
+
+// fielder is implemented by the levels of errorwrap.
+type fielder interface {
+	Fields() errorwrap.Fields
+}
+
+func init() {
+	errors.RegisterWrapperEncoderWithMessageType(wrapperKey, encodeLevel)
+	errors.RegisterWrapperDecoder(wrapperKey, decodeLevel)
+	errors.RegisterLeafEncoder(definitionKey, encodeDefinition)
+	errors.RegisterLeafDecoder(definitionKey, decodeDefinition)
+}
+
+// encodeLevel encodes the current level of an ErrorWrapper. The message is the message of the level, so decoders
+// unaware of errorwrap print it like ErrorWrapper.Error does.
+func encodeLevel(_ context.Context, err error) (string, []string, proto.Message, errbase.MessageType) {
+	ew, ok := err.(errorwrap.ErrorWrapper)
+	if !ok {
+		return err.Error(), nil, nil, errbase.FullMessage
+	}
+
+	kind := kindWrapper
+	entries := ew.CurrentError()
+	if ew.ParentError() == nil {
+		kind = kindRoot
+		if len(entries) > 0 {
+			entries = entries[1:]
+		}
+	}
+
+	var details []string
+	for _, entry := range entries {
+		details = append(details, encodeEntry(entry))
+	}
+	if ctxMsg := ew.ContextMessage(); ctxMsg != "" {
+		details = append(details, prefixContext+errorwrap.Redact(ctxMsg))
+	}
+	// FieldsOf redacts the values, and the fields of the level overwrite the fields of the lower levels.
+	redacted := errorwrap.FieldsOf(ew)
+	var fields errorwrap.Fields
+	if f, ok := ew.(fielder); ok {
+		fields = f.Fields()
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		details = append(details, prefixField+k+"="+fmt.Sprint(redacted[k]))
+	}
+	return err.Error(), []string{kind}, &errorspb.StringsPayload{Details: details}, errbase.FullMessage
+}
+
+// encodeEntry encodes an error of CurrentError by its code if it is a registered definition, or by its message. The
+// message is kept along with the code in case the code is not registered by the decoding process.
+func encodeEntry(err error) string {
+	if code := errorwrap.Code(err); code != "" {
+		if def, ok := errorwrap.DefinitionByCode(code); ok && def == err {
+			return prefixCode + code + " " + err.Error()
+		}
+	}
+	return prefixMessage + err.Error()
+}
+
+// decodeLevel rebuilds a level encoded by encodeLevel on top of cause.
+func decodeLevel(_ context.Context, cause error, _ string, safeDetails []string, payload proto.Message) error {
+	var (
+		entries []error
+		ctxMsg  string
+		fields  = errorwrap.Fields{}
+	)
+	if p, ok := payload.(*errorspb.StringsPayload); ok {
+		for _, detail := range p.Details {
+			switch {
+			case strings.HasPrefix(detail, prefixCode):
+				codeMsg := strings.SplitN(strings.TrimPrefix(detail, prefixCode), " ", 2)
+				if def, ok := errorwrap.DefinitionByCode(codeMsg[0]); ok {
+					entries = append(entries, def)
+					continue
+				}
+				entries = append(entries, errorwrap.New(codeMsg[len(codeMsg)-1], errorwrap.WithCode(codeMsg[0])))
+			case strings.HasPrefix(detail, prefixMessage):
+				entries = append(entries, stderrors.New(strings.TrimPrefix(detail, prefixMessage)))
+			case strings.HasPrefix(detail, prefixContext):
+				ctxMsg = strings.TrimPrefix(detail, prefixContext)
+			case strings.HasPrefix(detail, prefixField):
+				kv := strings.SplitN(strings.TrimPrefix(detail, prefixField), "=", 2)
+				if len(kv) == 2 {
+					fields[kv[0]] = kv[1]
+				}
+			}
+		}
+	}
+
+	var err error
+	if len(safeDetails) > 0 && safeDetails[0] == kindRoot {
+		err = errorwrap.NewErrorWithMessage(ctxMsg, append([]error{cause}, entries...)...)
+	} else {
+		err = errorwrap.WrapWithMessage(cause, ctxMsg, entries...)
+	}
+	if len(fields) > 0 {
+		err = errorwrap.AppendFields(err, fields)
+	}
+	return err
+}
+
+// encodeDefinition encodes an ErrorDefinition by its message and its code.
+func encodeDefinition(_ context.Context, err error) (string, []string, proto.Message) {
+	if code := errorwrap.Code(err); code != "" {
+		return err.Error(), []string{code}, nil
+	}
+	return err.Error(), nil, nil
+}
+
+// decodeDefinition returns the definition registered with the encoded code, so its identity is kept. Otherwise a new
+// definition is created.
+func decodeDefinition(_ context.Context, msg string, safeDetails []string, _ proto.Message) error {
+	if len(safeDetails) == 0 {
+		return errorwrap.New(msg)
+	}
+	if def, ok := errorwrap.DefinitionByCode(safeDetails[0]); ok {
+		return def
+	}
+	return errorwrap.New(msg, errorwrap.WithCode(safeDetails[0]))
+}
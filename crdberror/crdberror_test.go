@@ -0,0 +1,124 @@
+package crdberror
+
+import (
+	"context"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorUserNotFound = errorwrap.New("error user not found", errorwrap.WithCode("USER_NOT_FOUND"))
+	ErrorQuota        = errorwrap.New("error quota exceeded", errorwrap.WithCode("QUOTA_EXCEEDED"))
+	ErrorDomain       = errorwrap.New("error domain layer", errorwrap.WithCode("DOMAIN"))
+	ErrorUnregistered = errorwrap.New("error unregistered", errorwrap.WithCode("UNREGISTERED"))
+)
+
+func init() {
+	errorwrap.RegisterDefinition(ErrorUserNotFound, ErrorQuota, ErrorDomain)
+}
+
+func roundTrip(err error) error {
+	ctx := context.Background()
+	return errors.DecodeError(ctx, errors.EncodeError(ctx, err))
+}
+
+func TestRoundTrip(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantIs      []error
+		wantMessage string
+	}{
+		{
+			name:        "root level",
+			args:        args{err: errorwrap.NewErrorWithMessage("user 42", ErrorUserNotFound, ErrorQuota)},
+			wantIs:      []error{ErrorUserNotFound, ErrorQuota},
+			wantMessage: " -  error user not found\n    error quota exceeded\n    context: user 42",
+		},
+		{
+			name:        "wrapper levels",
+			args:        args{err: errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorDomain)},
+			wantIs:      []error{ErrorUserNotFound, ErrorDomain},
+			wantMessage: " -  error domain layer",
+		},
+		{
+			name:        "unregistered definition",
+			args:        args{err: errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorUnregistered)},
+			wantIs:      []error{ErrorUserNotFound},
+			wantMessage: " -  error unregistered",
+		},
+		{
+			name:        "multierror flattened by Wrap",
+			args:        args{err: errorwrap.Wrap(multierror.Append(nil, ErrorUserNotFound, ErrorQuota), ErrorDomain)},
+			wantIs:      []error{ErrorUserNotFound, ErrorQuota, ErrorDomain},
+			wantMessage: " -  error domain layer",
+		},
+		{
+			name:        "cockroachdb root cause",
+			args:        args{err: errorwrap.Wrap(errors.New("connection refused"), ErrorDomain)},
+			wantIs:      []error{ErrorDomain},
+			wantMessage: " -  error domain layer",
+		},
+		{
+			name:        "cockroachdb wrapper",
+			args:        args{err: errors.Wrap(errorwrap.NewError(ErrorUserNotFound), "find user")},
+			wantIs:      []error{ErrorUserNotFound},
+			wantMessage: "find user:  -  error user not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundTrip(tt.args.err)
+			for _, target := range tt.wantIs {
+				assert.True(t, errorwrap.Is(got, target), target)
+				assert.True(t, errors.Is(got, target), target)
+			}
+			assert.Equal(t, tt.wantMessage, got.Error())
+			assert.Equal(t, errorwrap.Code(tt.args.err), errorwrap.Code(got))
+		})
+	}
+}
+
+func TestRoundTripLevels(t *testing.T) {
+	err := errorwrap.Wrap(multierror.Append(nil, ErrorUserNotFound, errors.New("standard error")), ErrorDomain)
+	err = errorwrap.AppendFields(err, errorwrap.Fields{"user_id": 42})
+	err = errorwrap.WrapWithMessage(err, "handle request", ErrorQuota)
+
+	got := roundTrip(err)
+
+	assert.Equal(t, "42", errorwrap.FieldsOf(got)["user_id"])
+	assert.Contains(t, fmt.Sprintf("%+s", got), "context: handle request")
+
+	level := got.(errorwrap.ErrorWrapper)
+	assert.Equal(t, []error{ErrorQuota}, level.CurrentError())
+	level = level.ParentError()
+	assert.Equal(t, []error{ErrorDomain}, level.CurrentError())
+	level = level.ParentError()
+	if assert.Len(t, level.CurrentError(), 2) {
+		assert.Equal(t, ErrorUserNotFound, level.CurrentError()[0])
+		assert.Equal(t, "standard error", level.CurrentError()[1].Error())
+	}
+	assert.Nil(t, level.ParentError())
+}
+
+func TestRoundTripRedaction(t *testing.T) {
+	errorwrap.RedactFields("crdb_password")
+	errorwrap.RedactPatterns(errorwrap.PatternEmail)
+	err := errorwrap.AppendFields(errorwrap.NewErrorWithMessage("user a@b.io", ErrorUserNotFound),
+		errorwrap.Fields{"crdb_password": "secret"})
+
+	enc := errors.EncodeError(context.Background(), err)
+	assert.NotContains(t, enc.String(), "secret")
+	assert.NotContains(t, enc.String(), "a@b.io")
+
+	got := roundTrip(err)
+	assert.Equal(t, "[REDACTED]", errorwrap.FieldsOf(got)["crdb_password"])
+	assert.Equal(t, "user [REDACTED]", got.(errorwrap.ErrorWrapper).ContextMessage())
+}
@@ -0,0 +1,106 @@
+package errorwrap
+
+import (
+	"reflect"
+)
+
+type detailer interface {
+	Details() []interface{}
+}
+
+// AppendDetails appends typed payloads, e.g. validation violations or retry information, into the current level of
+// errWrapper. It will return the same errWrapper instance or new instance if errWrapper is not an ErrorWrapper. If
+// errWrapper is nil then AppendDetails returns nil.
+func AppendDetails(errWrapper error, details ...interface{}) error {
+	if errWrapper == nil {
+		return nil
+	}
+	ew, ok := errWrapper.(*errorWrapper)
+	if !ok || ew == nil {
+		ew = newRootWrapper(callStack(), errWrapper)
+		if ew == nil {
+			return nil
+		}
+	}
+	for _, detail := range details {
+		if detail == nil {
+			continue
+		}
+		ew.details = append(ew.details, detail)
+	}
+	return ew
+}
+
+// Details returns all typed payloads of err, ordered from current level to the root of ErrorWrapper stack.
+func Details(err error) []interface{} {
+	var details []interface{}
+	walkDetails(err, false, func(detail interface{}) bool {
+		details = append(details, detail)
+		return false
+	})
+	return details
+}
+
+// Detail finds the first payload of err that is assignable to the type pointed to by target, and if one is found,
+// sets target to that payload and returns true. It will find recursively from current level to the root of
+// ErrorWrapper stack. In each level the payloads of the level are checked before the CurrentError entries, and an
+// entry matches if the entry itself or one of its payloads is assignable to target.
+//
+// Detail panics if target is not a non-nil pointer.
+//
+//	var quota QuotaFailure
+//	if errorwrap.Detail(err, &quota) {
+//		// use quota
+//	}
+func Detail(err error, target interface{}) bool {
+	if target == nil {
+		panic("errorwrap: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errorwrap: target must be a non-nil pointer")
+	}
+	targetType := val.Type().Elem()
+
+	return walkDetails(err, true, func(detail interface{}) bool {
+		if reflect.TypeOf(detail).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(detail))
+			return true
+		}
+		return false
+	})
+}
+
+// walkDetails calls fn for every payload of err until fn returns true. If entries is true, the errors that are not
+// ErrorWrapper are passed to fn as well.
+func walkDetails(err error, entries bool, fn func(detail interface{}) bool) bool {
+	for ; err != nil; err = Unwrap(err) {
+		ew, ok := err.(ErrorWrapper)
+		if !ok {
+			if entries && fn(err) {
+				return true
+			}
+			continue
+		}
+		if d, ok := err.(detailer); ok {
+			for _, detail := range d.Details() {
+				if fn(detail) {
+					return true
+				}
+			}
+		}
+		for _, curErr := range ew.CurrentError() {
+			if d, ok := curErr.(detailer); ok {
+				for _, detail := range d.Details() {
+					if fn(detail) {
+						return true
+					}
+				}
+			}
+			if _, ok := curErr.(ErrorWrapper); entries && !ok && fn(curErr) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package errorwrap
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testQuotaFailure struct {
+	Subject string
+	Limit   int
+}
+
+type testRetryInfo struct {
+	RetryDelay time.Duration
+}
+
+type testCustomError struct {
+	Reason string
+}
+
+func (e *testCustomError) Error() string {
+	return "custom error: " + e.Reason
+}
+
+func TestAppendDetails(t *testing.T) {
+	assert.Nil(t, AppendDetails(nil, testRetryInfo{}))
+
+	err := AppendDetails(NewError(ErrorInfraDatabase), testRetryInfo{RetryDelay: time.Second}, nil)
+	assert.Equal(t, []interface{}{testRetryInfo{RetryDelay: time.Second}}, err.(detailer).Details())
+
+	same := AppendDetails(err, testQuotaFailure{Subject: "user:1", Limit: 10})
+	assert.Equal(t, err, same)
+	assert.Len(t, same.(detailer).Details(), 2)
+
+	fromDefinition := AppendDetails(ErrorTestA, testRetryInfo{})
+	assert.True(t, IsExact(fromDefinition, ErrorTestA))
+	assert.Len(t, fromDefinition.(detailer).Details(), 1)
+}
+
+func TestDetail(t *testing.T) {
+	root := AppendDetails(NewError(ErrorInfraDatabase, &testCustomError{Reason: "timeout"}), testRetryInfo{RetryDelay: time.Second})
+	domain := AppendDetails(Wrap(root, ErrorDomain), testQuotaFailure{Subject: "user:1", Limit: 10}, testRetryInfo{RetryDelay: time.Minute})
+	usecase := Wrap(domain, ErrorUseCase, NewError(ErrorTestA))
+
+	var retryInfo testRetryInfo
+	assert.True(t, Detail(usecase, &retryInfo))
+	assert.Equal(t, time.Minute, retryInfo.RetryDelay)
+
+	assert.True(t, Detail(root, &retryInfo))
+	assert.Equal(t, time.Second, retryInfo.RetryDelay)
+
+	var quota testQuotaFailure
+	assert.True(t, Detail(usecase, &quota))
+	assert.Equal(t, testQuotaFailure{Subject: "user:1", Limit: 10}, quota)
+	assert.False(t, Detail(root, &quota))
+
+	var custom *testCustomError
+	assert.True(t, Detail(usecase, &custom))
+	assert.Equal(t, "timeout", custom.Reason)
+
+	var detail interface{}
+	assert.True(t, Detail(usecase, &detail))
+
+	assert.Panics(t, func() { Detail(usecase, nil) })
+	assert.Panics(t, func() { Detail(usecase, quota) })
+}
+
+func TestDetails(t *testing.T) {
+	root := AppendDetails(NewError(ErrorInfraDatabase), testRetryInfo{RetryDelay: time.Second})
+	entry := AppendDetails(NewError(ErrorTestA), testQuotaFailure{Limit: 1})
+	usecase := AppendDetails(Wrap(root, ErrorUseCase, entry), testRetryInfo{RetryDelay: time.Minute})
+
+	assert.Nil(t, Details(appLayer()))
+	assert.Equal(t, []interface{}{
+		testRetryInfo{RetryDelay: time.Minute},
+		testQuotaFailure{Limit: 1},
+		testRetryInfo{RetryDelay: time.Second},
+	}, Details(usecase))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 )
 
 var (
@@ -17,6 +18,16 @@ type ErrorDefinition interface {
 	fmt.Formatter
 }
 
+// DefinitionOption configures an ErrorDefinition created by New.
+type DefinitionOption func(d *errorDefinition)
+
+// WithPublicMessage sets the user-safe message of an ErrorDefinition. Error() keeps returning the internal message.
+func WithPublicMessage(message string) DefinitionOption {
+	return func(d *errorDefinition) {
+		d.publicMsg = message
+	}
+}
+
 // ErrorWrapper is a multi level error. It is built like a stack (vertical from bottom to top) that contains multiple
 // ErrorDefinition (horizontal) in each level. If there is a 3 level, then each level will have an ErrorWrapper
 // instance with one ErrorDefinition (CurrentError) minimum.
@@ -46,14 +57,44 @@ type ErrorWrapper interface {
 	fmt.Formatter
 }
 
+// WithCode sets the stable code of an ErrorDefinition, e.g. "USER_NOT_FOUND". The code identifies the definition
+// outside the process, e.g. in message catalogs.
+func WithCode(code string) DefinitionOption {
+	return func(d *errorDefinition) {
+		d.code = code
+	}
+}
+
+// Sensitive marks an ErrorDefinition as sensitive. Its message is masked in Error() and in the formatted output unless
+// the error is formatted through Unsafe.
+func Sensitive() DefinitionOption {
+	return func(d *errorDefinition) {
+		d.sensitive = true
+	}
+}
+
 type errorDefinition struct {
-	msg string
+	msg       string
+	publicMsg string
+	code      string
+	category  Category
+	sensitive bool
+	retry     retryMark
+	severity  Severity
+	layer     string
 }
 
 func (e *errorDefinition) Error() string {
+	if e.sensitive {
+		return redactedText
+	}
 	return e.msg
 }
 
+func (e *errorDefinition) PublicMessage() string {
+	return e.publicMsg
+}
+
 func (e *errorDefinition) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
@@ -68,6 +109,11 @@ func (e *errorDefinition) Format(s fmt.State, verb rune) {
 type errorWrapper struct {
 	errors      []error
 	contextMsg  string
+	fields      Fields
+	details     []interface{}
+	retry       retryMark
+	layer       string
+	time        time.Time
 	rootCause   ErrorWrapper
 	parentError ErrorWrapper
 	*stack
@@ -93,37 +139,62 @@ func (e *errorWrapper) StackTrace() StackTrace {
 	return e.stack.StackTrace()
 }
 
+// Fields returns the fields of the current level only, see FieldsOf.
+func (e *errorWrapper) Fields() Fields {
+	return e.fields
+}
+
+// Details returns the payloads of the current level only, see Details.
+func (e *errorWrapper) Details() []interface{} {
+	return e.details
+}
+
 func (e *errorWrapper) Error() string {
+	return e.message(true)
+}
+
+func (e *errorWrapper) message(redact bool) string {
 	str := ""
 	if len(e.errors) <= 0 {
 		return ""
 	} else if len(e.errors) == 1 {
-		str += multilineSeparator + e.errors[0].Error()
+		str += multilineSeparator + errorMessage(e.errors[0], redact)
 	} else {
 		for i, err := range e.errors {
 			switch i {
 			case 0:
-				str += multilineSeparator + err.Error()
+				str += multilineSeparator + errorMessage(err, redact)
 			default:
-				str += "\n" + multilineIndent + err.Error()
+				str += "\n" + multilineIndent + errorMessage(err, redact)
 			}
 		}
 	}
 
 	if e.contextMsg != "" {
-		str += "\n" + multilineIndent + "context: " + e.contextMsg
+		str += "\n" + multilineIndent + "context: " + contextMessage(e.contextMsg, redact)
 	}
 	return str
 }
 
-func (e *errorWrapper) fullError() string {
-	str := e.Error()
+// fullError returns the messages of every level along with its layer if the layer is set using SetLayer, WithLayer or
+// RegisterLayer. If times is true, the creation time of each level is included.
+func (e *errorWrapper) fullError(redact bool, times bool) string {
+	str := e.message(redact)
+	if layer := e.resolveLayer(false); layer != "" {
+		str += "\n" + multilineIndent + "layer: " + layer
+	}
+	if line := e.timeLine(); times && line != "" {
+		str += "\n" + multilineIndent + "time: " + line
+	}
+	if len(e.fields) > 0 {
+		str += "\n" + multilineIndent + "fields: " + formatFields(e.fields, redact)
+	}
 	if e.parentError != nil {
 		str += "\n"
 		if ec, ok := e.parentError.(*errorWrapper); ok && ec != nil {
-			str += ec.fullError()
+			str += ec.fullError(redact, times)
 		} else {
-			str += e.parentError.Error()
+			str += errorMessage(e.parentError, redact)
 		}
 	}
 	return str
@@ -159,43 +230,54 @@ func (e *errorWrapper) As(target interface{}) bool {
 }
 
 func (e *errorWrapper) Format(s fmt.State, verb rune) {
+	e.format(s, verb, true, nil)
+}
+
+// format formats the error. If normalize is not nil, the stack trace is printed using normalize.
+func (e *errorWrapper) format(s fmt.State, verb rune, redact bool, normalize *NormalizeOptions) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v\n\n", e.fullError())
+			times := normalize == nil || !normalize.HideTimes
+			fmt.Fprintf(s, "%+v\n\n", e.fullError(redact, times))
+			st := e.stack
 			if rc, ok := e.rootCause.(*errorWrapper); ok && rc != nil {
-				rc.stack.Format(s, verb)
+				st = rc.stack
+			}
+			if normalize != nil {
+				normalize.formatStack(s, st)
 			} else {
-				e.stack.Format(s, verb)
+				st.Format(s, verb)
 			}
 			return
 		}
 		fallthrough
 	case 's':
 		if s.Flag('+') {
-			io.WriteString(s, e.fullError())
+			io.WriteString(s, e.fullError(redact, false))
 			return
 		}
-		io.WriteString(s, e.Error())
+		io.WriteString(s, e.message(redact))
 	case 'q':
-		fmt.Fprintf(s, "%q", e.Error())
+		fmt.Fprintf(s, "%q", e.message(redact))
 	}
 }
 
 // New creates an ErrorDefinition
-func New(message string) error {
-	return &errorDefinition{
+func New(message string, opts ...DefinitionOption) error {
+	def := &errorDefinition{
 		msg: message,
 	}
+	for _, opt := range opts {
+		opt(def)
+	}
+	return def
 }
 
 func newErrorWrapper(err ...error) *errorWrapper {
 	var errs []error
 	for _, e := range err {
-		if e == nil {
-			continue
-		}
-		errs = append(errs, e)
+		errs = flattenErrors(errs, e)
 	}
 
 	if len(errs) == 0 {
@@ -203,20 +285,37 @@ func newErrorWrapper(err ...error) *errorWrapper {
 	}
 
 	return &errorWrapper{
-		errors: errs,
+		errors: classifyErrors(errs),
+		time:   captureTime(),
 	}
 }
 
+// newRootWrapper creates a base or root ErrorWrapper containing err and notifies the creation hooks. The stack trace
+// of github.com/pkg/errors carried by err is imported if there is one, otherwise st is used. If there is no error to
+// contain, e.g. err is an empty multi-error, newRootWrapper returns nil.
+func newRootWrapper(st *stack, err ...error) *errorWrapper {
+	ew := newErrorWrapper(err...)
+	if ew == nil {
+		return nil
+	}
+	if !ew.importStack() {
+		ew.stack = st
+	}
+	notifyCreated(ew)
+	return ew
+}
+
 // NewError creates a base or root ErrorWrapper.
 //
 // ErrorWrapper.CurrentError is populated with err. If err is nil then NewError returns nil.
 // It is recommended to pass ErrorDefinition as err arguments.
+// If an error of err carries a stack trace of github.com/pkg/errors, the stack trace is imported instead of capturing
+// a new one. A *multierror.Error of github.com/hashicorp/go-multierror is flattened into its errors.
 func NewError(err ...error) error {
-	errWrap := newErrorWrapper(err...)
+	errWrap := newRootWrapper(callStack(), err...)
 	if errWrap == nil {
 		return nil
 	}
-	errWrap.stack = callStack()
 	return errWrap
 }
 
@@ -230,8 +329,11 @@ func NewErrorWithMessage(contextMessage string, err ...error) error {
 	if errWrap == nil {
 		return nil
 	}
-	errWrap.stack = callStack()
+	if !errWrap.importStack() {
+		errWrap.stack = callStack()
+	}
 	errWrap.contextMsg = contextMessage
+	notifyCreated(errWrap)
 	return errWrap
 }
 
@@ -240,52 +342,59 @@ func NewErrorWithMessage(contextMessage string, err ...error) error {
 func AppendInto(errWrapper error, err ...error) error {
 	ew, ok := errWrapper.(*errorWrapper)
 	if ok && ew != nil {
-		ew.errors = append(ew.errors, err...)
+		errs := ew.errors
+		for _, e := range err {
+			errs = flattenErrors(errs, e)
+		}
+		ew.errors = classifyErrors(errs)
 	} else {
-		ew = newErrorWrapper(err...)
+		ew = newRootWrapper(callStack(), err...)
 		if ew == nil {
 			return nil
 		}
-		ew.stack = callStack()
 	}
 	return ew
 }
 
 // Wrap returns an ErrorWrapper{CurrentError: wrapper, ParentError: parent, RootCause: parent.RootCause}.
 // It is recommended to pass ErrorDefinition as err arguments.
+// If parent is not an ErrorWrapper and carries a stack trace of github.com/pkg/errors, the stack trace is imported
+// into the root level instead of capturing a new one. A *multierror.Error of github.com/hashicorp/go-multierror given
+// as parent or in err is flattened into the errors of a single level.
 func Wrap(parent error, err ...error) error {
-	ec := WrapWithMessage(parent, "", err...)
-	if ec == nil {
-		return nil
-	}
-
-	if p, ok := parent.(*errorWrapper); !ok || p == nil {
-		if parentErr, ok2 := ec.(*errorWrapper).parentError.(*errorWrapper); ok2 && parentErr != nil {
-			parentErr.stack = callStack()
-		}
-	}
-	ec.(*errorWrapper).stack = callStack()
-	return ec
+	return wrapWithMessage(parent, "", callStack(), err...)
 }
 
 // WrapWithMessage returns an ErrorWrapper{CurrentError: wrapper, ParentError: parent, RootCause: parent.RootCause, ContextMessage: contextMessage}.
 // It is recommended to pass ErrorDefinition as err arguments.
 func WrapWithMessage(parent error, contextMessage string, err ...error) error {
+	return wrapWithMessage(parent, contextMessage, callStack(), err...)
+}
+
+// wrapWithMessage implements Wrap and WrapWithMessage. st is the stack of the created levels.
+func wrapWithMessage(parent error, contextMessage string, st *stack, err ...error) error {
 	if parent == nil && len(err) == 0 {
 		return nil
 	}
 
+	created := false
 	parentWrapper, ok := parent.(*errorWrapper)
 	if !ok {
 		parentWrapper = newErrorWrapper(parent)
 		if parentWrapper != nil {
-			parentWrapper.stack = callStack()
+			if !parentWrapper.importStack() {
+				parentWrapper.stack = st
+			}
+			created = true
 		}
 	}
 
 	currError := newErrorWrapper(err...)
 	if currError == nil {
 		if parentWrapper != nil {
+			if created {
+				notifyCreated(parentWrapper)
+			}
 			return parentWrapper
 		}
 		return nil
@@ -301,8 +410,11 @@ func WrapWithMessage(parent error, contextMessage string, err ...error) error {
 	}
 
 	currError.contextMsg = contextMessage
-	currError.stack = callStack()
+	currError.stack = st
 
+	if created || parentWrapper == nil {
+		notifyCreated(currError)
+	}
 	return currError
 }
 
@@ -329,6 +441,25 @@ func Is(err error, target error) bool {
 	return errors.Is(err, target)
 }
 
+// Code returns the code of err if err is an ErrorDefinition created with WithCode, otherwise it returns an empty
+// string.
+func Code(err error) string {
+	if def := definitionOf(err); def != nil {
+		return def.code
+	}
+	return ""
+}
+
+// DefinitionMessage returns the message err was defined with if err is an ErrorDefinition, otherwise it returns an
+// empty string. The placeholders of an instantiated Template are kept and sensitive messages are not masked, so the
+// result only depends on the definition, e.g. for a metric label. It must not be shown to users.
+func DefinitionMessage(err error) string {
+	if def := definitionOf(err); def != nil {
+		return def.msg
+	}
+	return ""
+}
+
 // IsExact checks whether target is placed in current level error of err (ErrorWrapper) or not.
 func IsExact(err error, target error) bool {
 	if curWrapper, ok := err.(ErrorWrapper); ok && (curWrapper == target || curWrapper.Is(target)) {
@@ -345,3 +476,36 @@ func As(err error, target interface{}) bool {
 func Unwrap(err error) error {
 	return errors.Unwrap(err)
 }
+
+// definitionOf returns the errorDefinition of err, including the definition of an instantiated Template.
+func definitionOf(err error) *errorDefinition {
+	switch e := err.(type) {
+	case *errorDefinition:
+		return e
+	case *templateDefinition:
+		return e.errorDefinition
+	case *templateError:
+		return e.template.errorDefinition
+	}
+	return nil
+}
+
+// walkErrors calls fn for every error of err that is not an ErrorWrapper, from current level to the root of
+// ErrorWrapper stack, until fn returns true. The entries of a nested ErrorWrapper are visited in place.
+func walkErrors(err error, fn func(err error) bool) bool {
+	for ; err != nil; err = Unwrap(err) {
+		ew, ok := err.(ErrorWrapper)
+		if !ok {
+			if fn(err) {
+				return true
+			}
+			continue
+		}
+		for _, curErr := range ew.CurrentError() {
+			if walkErrors(curErr, fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
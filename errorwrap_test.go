@@ -8,8 +8,6 @@ import (
 )
 
 var (
-	ErrorCommonNotFound = New("error not found")
-
 	ErrorMysqlDb = errors.New("error database mysql")
 	ErrorRedisDb = New("error database redis")
 
@@ -0,0 +1,184 @@
+// Package errorwraptest provides assertion helpers for the shape of errorwrap.ErrorWrapper chains.
+//
+//	errorwraptest.AssertChain(t, err, []errorwraptest.Level{
+//		{Errors: []error{ErrorUseCase}},
+//		{Errors: []error{ErrorDomain}, Context: "find user"},
+//		{Errors: []error{ErrorInfraDatabase, ErrorCommonNotFound}},
+//	})
+package errorwraptest
+
+import (
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/pmezard/go-difflib/difflib"
+	"strings"
+)
+
+// TestingT is the subset of testing.TB used by the assertions.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+type tHelper interface {
+	Helper()
+}
+
+// Level is the expected shape of a level of an ErrorWrapper chain. Errors are compared by identity, in order.
+type Level struct {
+	Errors  []error
+	Context string
+}
+
+// Chain returns the levels of err, from current level to the root of ErrorWrapper stack. An error that is not an
+// ErrorWrapper becomes a level containing only that error.
+func Chain(err error) []Level {
+	var levels []Level
+	for ; err != nil; err = errorwrap.Unwrap(err) {
+		if ew, ok := err.(errorwrap.ErrorWrapper); ok {
+			levels = append(levels, Level{Errors: ew.CurrentError(), Context: ew.ContextMessage()})
+			continue
+		}
+		levels = append(levels, Level{Errors: []error{err}})
+	}
+	return levels
+}
+
+// AssertChain asserts that the chain of err has exactly the levels of want, from current level to the root. On
+// failure it prints a diff of the expected and actual chains.
+func AssertChain(t TestingT, err error, want []Level, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	got := Chain(err)
+	if chainEqual(want, got) {
+		return true
+	}
+	expected, actual := renderChain(want, nil), renderChain(got, want)
+	return fail(t, fmt.Sprintf("Error chains are not equal:\nexpected:\n%s\nactual:\n%s\ndiff:\n%s",
+		indent(expected), indent(actual), diff(expected, actual)), msgAndArgs...)
+}
+
+// AssertIsAt asserts that target is placed in the CurrentError of the level at depth of err, where depth 0 is the
+// current level.
+func AssertIsAt(t TestingT, err error, target error, depth int, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	levels := Chain(err)
+	if depth >= 0 && depth < len(levels) && containsError(levels[depth].Errors, target) {
+		return true
+	}
+
+	found := "not found in the chain"
+	for i, level := range levels {
+		if containsError(level.Errors, target) {
+			found = fmt.Sprintf("found at depth %d", i)
+			break
+		}
+	}
+	return fail(t, fmt.Sprintf("Error %q is not at depth %d, %s:\n%s", target, depth, found,
+		indent(renderChain(levels, nil))), msgAndArgs...)
+}
+
+// AssertContext asserts that the ContextMessage of the current level of err is msg.
+func AssertContext(t TestingT, err error, msg string, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	ew, ok := err.(errorwrap.ErrorWrapper)
+	if !ok {
+		return fail(t, fmt.Sprintf("Error is not an ErrorWrapper: %#v", err), msgAndArgs...)
+	}
+	if ew.ContextMessage() == msg {
+		return true
+	}
+	return fail(t, fmt.Sprintf("Context messages are not equal:\nexpected: %q\nactual  : %q", msg,
+		ew.ContextMessage()), msgAndArgs...)
+}
+
+func fail(t TestingT, message string, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if extra := messageFromMsgAndArgs(msgAndArgs...); extra != "" {
+		message += "\nmessages:\n" + indent(extra)
+	}
+	t.Errorf("\n%s", message)
+	return false
+}
+
+func messageFromMsgAndArgs(msgAndArgs ...interface{}) string {
+	switch len(msgAndArgs) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprint(msgAndArgs[0])
+	default:
+		return fmt.Sprintf(fmt.Sprint(msgAndArgs[0]), msgAndArgs[1:]...)
+	}
+}
+
+func chainEqual(want, got []Level) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i].Context != got[i].Context || len(want[i].Errors) != len(got[i].Errors) {
+			return false
+		}
+		for j := range want[i].Errors {
+			if want[i].Errors[j] != got[i].Errors[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// renderChain renders levels one line per error. An error whose message equals the expected one but which is another
+// instance is marked, because the rendered lines would be equal otherwise.
+func renderChain(levels []Level, want []Level) string {
+	var lines []string
+	for i, level := range levels {
+		lines = append(lines, fmt.Sprintf("level %d:", i))
+		for j, err := range level.Errors {
+			line := fmt.Sprintf("  %q", errorwrap.Unsafe(err))
+			if i < len(want) && j < len(want[i].Errors) && want[i].Errors[j] != err &&
+				fmt.Sprint(errorwrap.Unsafe(want[i].Errors[j])) == fmt.Sprint(errorwrap.Unsafe(err)) {
+				line += " (different instance)"
+			}
+			lines = append(lines, line)
+		}
+		if level.Context != "" {
+			lines = append(lines, fmt.Sprintf("  context: %q", level.Context))
+		}
+	}
+	if len(lines) == 0 {
+		return "<nil>"
+	}
+	return strings.Join(lines, "\n")
+}
+
+func diff(expected, actual string) string {
+	d, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(expected + "\n"),
+		B:        difflib.SplitLines(actual + "\n"),
+		FromFile: "Expected",
+		ToFile:   "Actual",
+		Context:  1,
+	})
+	return d
+}
+
+func indent(s string) string {
+	return "\t" + strings.ReplaceAll(s, "\n", "\n\t")
+}
+
+func containsError(errs []error, target error) bool {
+	for _, err := range errs {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package errorwraptest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorInfra   = errorwrap.New("error infra layer")
+	ErrorDomain  = errorwrap.New("error domain layer")
+	ErrorUseCase = errorwrap.New("error usecase layer")
+	ErrorStd     = errors.New("standard error")
+)
+
+type fakeT struct {
+	messages []string
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.messages = append(t.messages, fmt.Sprintf(format, args...))
+}
+
+func newTestChain() error {
+	root := errorwrap.NewError(ErrorInfra, ErrorStd)
+	return errorwrap.Wrap(errorwrap.WrapWithMessage(root, "find user", ErrorDomain), ErrorUseCase)
+}
+
+func TestAssertChain(t *testing.T) {
+	type args struct {
+		err  error
+		want []Level
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantOk      bool
+		wantMessage []string
+	}{
+		{
+			name: "equal",
+			args: args{err: newTestChain(), want: []Level{
+				{Errors: []error{ErrorUseCase}},
+				{Errors: []error{ErrorDomain}, Context: "find user"},
+				{Errors: []error{ErrorInfra, ErrorStd}},
+			}},
+			wantOk: true,
+		},
+		{
+			name:   "nil error",
+			args:   args{err: nil, want: nil},
+			wantOk: true,
+		},
+		{
+			name: "standard error",
+			args: args{err: fmt.Errorf("wrap: %w", ErrorStd), want: []Level{
+				{Errors: []error{ErrorStd}},
+			}},
+			wantMessage: []string{"+  \"wrap: standard error\"", "+level 1:"},
+		},
+		{
+			name: "wrong context and missing level",
+			args: args{err: newTestChain(), want: []Level{
+				{Errors: []error{ErrorUseCase}},
+				{Errors: []error{ErrorDomain}, Context: "find users"},
+			}},
+			wantMessage: []string{"Error chains are not equal", "-  context: \"find users\"", "+  context: \"find user\"", "+level 2:"},
+		},
+		{
+			name: "different instance",
+			args: args{err: newTestChain(), want: []Level{
+				{Errors: []error{errorwrap.New("error usecase layer")}},
+				{Errors: []error{ErrorDomain}, Context: "find user"},
+				{Errors: []error{ErrorInfra, ErrorStd}},
+			}},
+			wantMessage: []string{"+  \"error usecase layer\" (different instance)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := &fakeT{}
+			assert.Equal(t, tt.wantOk, AssertChain(ft, tt.args.err, tt.args.want))
+			if tt.wantOk {
+				assert.Empty(t, ft.messages)
+				return
+			}
+			assert.Len(t, ft.messages, 1)
+			for _, msg := range tt.wantMessage {
+				assert.Contains(t, ft.messages[0], msg)
+			}
+		})
+	}
+}
+
+func TestAssertIsAt(t *testing.T) {
+	err := newTestChain()
+
+	ft := &fakeT{}
+	assert.True(t, AssertIsAt(ft, err, ErrorUseCase, 0))
+	assert.True(t, AssertIsAt(ft, err, ErrorStd, 2))
+	assert.Empty(t, ft.messages)
+
+	assert.False(t, AssertIsAt(ft, err, ErrorDomain, 2))
+	assert.False(t, AssertIsAt(ft, err, errors.New("other"), 5, "lookup %s", "other"))
+	assert.Len(t, ft.messages, 2)
+	assert.Contains(t, ft.messages[0], `Error "error domain layer" is not at depth 2, found at depth 1`)
+	assert.Contains(t, ft.messages[1], "not found in the chain")
+	assert.Contains(t, ft.messages[1], "lookup other")
+}
+
+func TestAssertContext(t *testing.T) {
+	err := newTestChain()
+
+	ft := &fakeT{}
+	assert.True(t, AssertContext(ft, err, ""))
+	assert.True(t, AssertContext(ft, errorwrap.Unwrap(err), "find user"))
+	assert.Empty(t, ft.messages)
+
+	assert.False(t, AssertContext(ft, err, "find user"))
+	assert.False(t, AssertContext(ft, ErrorStd, "find user"))
+	assert.Len(t, ft.messages, 2)
+	assert.Contains(t, ft.messages[0], "expected: \"find user\"\nactual  : \"\"")
+	assert.Contains(t, ft.messages[1], "Error is not an ErrorWrapper")
+}
+
+func TestChain(t *testing.T) {
+	AssertChain(t, newTestChain(), []Level{
+		{Errors: []error{ErrorUseCase}},
+		{Errors: []error{ErrorDomain}, Context: "find user"},
+		{Errors: []error{ErrorInfra, ErrorStd}},
+	})
+	assert.Nil(t, Chain(nil))
+}
@@ -0,0 +1,52 @@
+package errorwraptest
+
+import (
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"os"
+	"path/filepath"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGolden write the golden files instead of comparing
+// them, e.g. ERRORWRAP_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "ERRORWRAP_UPDATE_GOLDEN"
+
+// AssertGolden asserts that got is equal to the content of goldenFile. If UpdateGoldenEnv is set, goldenFile is
+// written with got instead.
+func AssertGolden(t TestingT, goldenFile string, got string, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			return fail(t, fmt.Sprintf("Unable to create the directory of %s: %v", goldenFile, err), msgAndArgs...)
+		}
+		if err := os.WriteFile(goldenFile, []byte(got), 0o644); err != nil {
+			return fail(t, fmt.Sprintf("Unable to update %s: %v", goldenFile, err), msgAndArgs...)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		return fail(t, fmt.Sprintf("Unable to read %s: %v\nrun the test with %s=1 to create it", goldenFile, err,
+			UpdateGoldenEnv), msgAndArgs...)
+	}
+	if string(want) == got {
+		return true
+	}
+	return fail(t, fmt.Sprintf("Output does not match %s:\n%s\nrun the test with %s=1 to update it", goldenFile,
+		diff(string(want), got), UpdateGoldenEnv), msgAndArgs...)
+}
+
+// AssertFormatGolden formats err using format and errorwrap.Normalized with errorwrap.GoldenOptions, then asserts the
+// result using AssertGolden.
+//
+//	errorwraptest.AssertFormatGolden(t, "testdata/user_not_found.golden", "%+v", err)
+func AssertFormatGolden(t TestingT, goldenFile string, format string, err error, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	got := fmt.Sprintf(format, errorwrap.Normalized(err, errorwrap.GoldenOptions))
+	return AssertGolden(t, goldenFile, got, msgAndArgs...)
+}
@@ -0,0 +1,35 @@
+package errorwraptest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssertFormatGolden(t *testing.T) {
+	err := newTestChain()
+	AssertFormatGolden(t, "testdata/chain_verbose.golden", "%+v", err)
+	AssertFormatGolden(t, "testdata/chain_full.golden", "%+s", err)
+}
+
+func TestAssertGolden(t *testing.T) {
+	dir := t.TempDir()
+	goldenFile := filepath.Join(dir, "nested", "output.golden")
+
+	ft := &fakeT{}
+	assert.False(t, AssertGolden(ft, goldenFile, "output"))
+	assert.Contains(t, ft.messages[0], "run the test with "+UpdateGoldenEnv+"=1 to create it")
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	assert.True(t, AssertGolden(ft, goldenFile, "line 1\nline 2\n"))
+	content, err := os.ReadFile(goldenFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "line 1\nline 2\n", string(content))
+
+	t.Setenv(UpdateGoldenEnv, "")
+	assert.True(t, AssertGolden(ft, goldenFile, "line 1\nline 2\n"))
+	assert.False(t, AssertGolden(ft, goldenFile, "line 1\nline 3\n"))
+	assert.Len(t, ft.messages, 2)
+	assert.Contains(t, ft.messages[1], "-line 2\n+line 3")
+}
@@ -0,0 +1,14 @@
+// Command errorwrapvet runs the errorwrapvet analyzer. It can be run directly or by go vet:
+//
+//	errorwrapvet ./...
+//	go vet -vettool=$(which errorwrapvet) ./...
+package main
+
+import (
+	"github.com/anantadwi13/errorwrap/errorwrapvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(errorwrapvet.Analyzer)
+}
@@ -0,0 +1,330 @@
+// Package errorwrapvet defines an Analyzer that reports code that does not follow the conventions of errorwrap:
+//
+//   - errorwrap.New or errorwrap.NewTemplate called outside a package-level var declaration. Every call creates a new
+//     definition, so errorwrap.Is cannot match it.
+//   - errors.New passed to NewError, NewErrorWithMessage, AppendInto, Wrap or WrapWithMessage where an
+//     ErrorDefinition is recommended.
+//   - an error received from a function of another package returned as is, without errorwrap.Wrap. Errors received from
+//     the functions of the same package are expected to be wrapped already.
+//   - errors compared using == or != instead of errorwrap.Is.
+//
+// The last two checks only run in the packages importing errorwrap, since the other packages do not use its
+// conventions.
+package errorwrapvet
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const errorwrapPath = "github.com/anantadwi13/errorwrap"
+
+// Analyzer reports code that does not follow the conventions of errorwrap.
+var Analyzer = &analysis.Analyzer{
+	Name:     "errorwrapvet",
+	Doc:      "check the usage of github.com/anantadwi13/errorwrap",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// definitionArgs is the index of the first argument that is recommended to be an ErrorDefinition.
+var definitionArgs = map[string]int{
+	"NewError":            0,
+	"NewErrorWithMessage": 1,
+	"AppendInto":          1,
+	"Wrap":                1,
+	"WrapWithMessage":     2,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if pass.Pkg.Path() == errorwrapPath {
+		return nil, nil
+	}
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	stdDefinitions := stdErrorVars(pass)
+	usesErrorwrap := importsErrorwrap(pass)
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+		(*ast.BinaryExpr)(nil),
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+	}
+	insp.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			checkNewOutsideVar(pass, n, stack)
+			checkDefinitionArgs(pass, n, stdDefinitions)
+		case *ast.BinaryExpr:
+			if usesErrorwrap {
+				checkComparison(pass, n)
+			}
+		case *ast.FuncDecl:
+			if usesErrorwrap && n.Body != nil {
+				checkRawReturn(pass, n.Type, n.Body)
+			}
+		case *ast.FuncLit:
+			if usesErrorwrap {
+				checkRawReturn(pass, n.Type, n.Body)
+			}
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// checkNewOutsideVar reports errorwrap.New and errorwrap.NewTemplate called inside a function.
+func checkNewOutsideVar(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) {
+	name, ok := calleeOf(pass, call, errorwrapPath)
+	if !ok || (name != "New" && name != "NewTemplate") {
+		return
+	}
+	for _, n := range stack {
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			pass.Reportf(call.Pos(), "errorwrap.%s should be called in a package-level var declaration, "+
+				"otherwise every call creates a definition that errorwrap.Is cannot match", name)
+			return
+		}
+	}
+}
+
+// checkDefinitionArgs reports errors.New passed where an ErrorDefinition is recommended.
+func checkDefinitionArgs(pass *analysis.Pass, call *ast.CallExpr, stdDefinitions map[types.Object]bool) {
+	name, ok := calleeOf(pass, call, errorwrapPath)
+	if !ok {
+		return
+	}
+	first, ok := definitionArgs[name]
+	if !ok {
+		return
+	}
+	for i := first; i < len(call.Args); i++ {
+		arg := ast.Unparen(call.Args[i])
+		if isStdErrorsNew(pass, arg) {
+			pass.Reportf(arg.Pos(), "use an ErrorDefinition created by errorwrap.New instead of errors.New")
+			continue
+		}
+		if id, ok := arg.(*ast.Ident); ok && stdDefinitions[pass.TypesInfo.Uses[id]] {
+			pass.Reportf(arg.Pos(), "%s is created by errors.New, use errorwrap.New instead", id.Name)
+		}
+	}
+}
+
+// checkComparison reports errors compared using == or !=.
+func checkComparison(pass *analysis.Pass, expr *ast.BinaryExpr) {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+	if isNil(pass, expr.X) || isNil(pass, expr.Y) {
+		return
+	}
+	if isError(pass.TypesInfo.TypeOf(expr.X)) && isError(pass.TypesInfo.TypeOf(expr.Y)) {
+		pass.Reportf(expr.OpPos, "compare errors using errorwrap.Is instead of %s", expr.Op)
+	}
+}
+
+// checkRawReturn reports an error received from a call into another package and returned as is from an
+// "if err != nil" block. Only the last assignment of err before the if statement is taken into account.
+func checkRawReturn(pass *analysis.Pass, funcType *ast.FuncType, body *ast.BlockStmt) {
+	if !returnsError(pass, funcType) {
+		return
+	}
+
+	type assignment struct {
+		pos      token.Pos
+		fromCall bool
+	}
+	assignments := map[types.Object][]assignment{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				// a single call may assign several values, e.g. "n, err := find()"
+				rhs := n.Rhs[0]
+				if len(n.Rhs) == len(n.Lhs) {
+					rhs = n.Rhs[i]
+				}
+				call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+				fromCall := ok && callsOtherPackage(pass, call) && !isErrorwrapCall(pass, call)
+				if id, ok := lhs.(*ast.Ident); ok {
+					if obj := objectOf(pass, id); obj != nil && isError(obj.Type()) {
+						assignments[obj] = append(assignments[obj], assignment{pos: n.Pos(), fromCall: fromCall})
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.IfStmt:
+			obj := nonNilCheck(pass, n.Cond)
+			if obj == nil {
+				return true
+			}
+			var last *assignment
+			for i, a := range assignments[obj] {
+				if a.pos < n.Cond.Pos() {
+					last = &assignments[obj][i]
+				}
+			}
+			if last == nil || !last.fromCall {
+				return true
+			}
+			for _, stmt := range n.Body.List {
+				ret, ok := stmt.(*ast.ReturnStmt)
+				if !ok {
+					continue
+				}
+				for _, result := range ret.Results {
+					if id, ok := ast.Unparen(result).(*ast.Ident); ok && pass.TypesInfo.Uses[id] == obj {
+						pass.Reportf(id.Pos(), "%s is returned without errorwrap.Wrap", id.Name)
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// nonNilCheck returns the object of err in the condition "err != nil".
+func nonNilCheck(pass *analysis.Pass, cond ast.Expr) types.Object {
+	expr, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || expr.Op != token.NEQ {
+		return nil
+	}
+	x, y := expr.X, expr.Y
+	if isNil(pass, x) {
+		x, y = y, x
+	}
+	id, ok := ast.Unparen(x).(*ast.Ident)
+	if !ok || !isNil(pass, y) {
+		return nil
+	}
+	return pass.TypesInfo.Uses[id]
+}
+
+// stdErrorVars returns the package-level variables initialized using errors.New.
+func stdErrorVars(pass *analysis.Pass) map[types.Object]bool {
+	vars := map[types.Object]bool{}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, value := range vs.Values {
+					if i < len(vs.Names) && isStdErrorsNew(pass, ast.Unparen(value)) {
+						vars[pass.TypesInfo.Defs[vs.Names[i]]] = true
+					}
+				}
+			}
+		}
+	}
+	return vars
+}
+
+func isStdErrorsNew(pass *analysis.Pass, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	name, ok := calleeOf(pass, call, "errors")
+	return ok && name == "New"
+}
+
+// importsErrorwrap checks whether the package being analyzed imports errorwrap.
+func importsErrorwrap(pass *analysis.Pass) bool {
+	for _, imp := range pass.Pkg.Imports() {
+		if imp.Path() == errorwrapPath {
+			return true
+		}
+	}
+	return false
+}
+
+// callsOtherPackage checks whether call calls a function or a method declared in another package than the package
+// being analyzed. Calls of function values are not taken into account, since their package is unknown.
+func callsOtherPackage(pass *analysis.Pass, call *ast.CallExpr) bool {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return false
+	}
+	fn, ok := pass.TypesInfo.Uses[id].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg() != pass.Pkg
+}
+
+func isErrorwrapCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	_, ok := calleeOf(pass, call, errorwrapPath)
+	return ok
+}
+
+// calleeOf returns the name of the package-level function called by call if the function belongs to pkgPath.
+func calleeOf(pass *analysis.Pass, call *ast.CallExpr, pkgPath string) (string, bool) {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return "", false
+	}
+	fn, ok := pass.TypesInfo.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return "", false
+	}
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		return "", false
+	}
+	return fn.Name(), true
+}
+
+func returnsError(pass *analysis.Pass, funcType *ast.FuncType) bool {
+	if funcType.Results == nil {
+		return false
+	}
+	for _, field := range funcType.Results.List {
+		if isError(pass.TypesInfo.TypeOf(field.Type)) {
+			return true
+		}
+	}
+	return false
+}
+
+func objectOf(pass *analysis.Pass, id *ast.Ident) types.Object {
+	if obj := pass.TypesInfo.Defs[id]; obj != nil {
+		return obj
+	}
+	return pass.TypesInfo.Uses[id]
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func isError(t types.Type) bool {
+	return t != nil && types.Implements(t, errorType)
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	return pass.TypesInfo.Types[expr].IsNil()
+}
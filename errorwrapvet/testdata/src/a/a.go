@@ -0,0 +1,112 @@
+package a
+
+import (
+	"b"
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"io"
+)
+
+var (
+	ErrorDomain = errorwrap.New("error domain layer")
+	ErrorStd    = errors.New("standard error")
+)
+
+func find() error {
+	return nil
+}
+
+func newInFunction() error {
+	def := errorwrap.New("error inside function") // want `errorwrap.New should be called in a package-level var declaration`
+	return errorwrap.NewError(def)
+}
+
+func newTemplateInClosure() func() error {
+	return func() error {
+		return errorwrap.NewTemplate("user {id}") // want `errorwrap.NewTemplate should be called in a package-level var declaration`
+	}
+}
+
+func stdErrorsArgs(err error) error {
+	_ = errorwrap.NewError(errors.New("inline"))                        // want `use an ErrorDefinition created by errorwrap.New instead of errors.New`
+	_ = errorwrap.NewErrorWithMessage("context", ErrorDomain, ErrorStd) // want `ErrorStd is created by errors.New, use errorwrap.New instead`
+	_ = errorwrap.AppendInto(err, (errors.New("inline")))               // want `use an ErrorDefinition created by errorwrap.New instead of errors.New`
+	_ = errorwrap.WrapWithMessage(err, "context", errors.New("inline")) // want `use an ErrorDefinition created by errorwrap.New instead of errors.New`
+	return errorwrap.Wrap(errors.New("parent is allowed"), ErrorDomain)
+}
+
+func rawReturn() error {
+	err := b.Find()
+	if err != nil {
+		return err // want `err is returned without errorwrap.Wrap`
+	}
+	return nil
+}
+
+func rawReturnMethod(repo b.Repository) error {
+	if err := repo.Find(); err != nil {
+		return err // want `err is returned without errorwrap.Wrap`
+	}
+	return nil
+}
+
+func rawReturnMultiple() (int, error) {
+	n, err := 0, b.Find()
+	if err != nil {
+		return n, err // want `err is returned without errorwrap.Wrap`
+	}
+	var err2 error
+	if err2 = b.Find(); err2 != nil {
+		return 0, err2 // want `err2 is returned without errorwrap.Wrap`
+	}
+	count, err := b.Count()
+	if err != nil {
+		return 0, err // want `err is returned without errorwrap.Wrap`
+	}
+	return n + count, nil
+}
+
+func samePackageReturn() error {
+	// find belongs to the same layer, so its error is expected to be wrapped already
+	err := find()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func functionValueReturn(fn func() error) error {
+	if err := fn(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func wrappedReturn() error {
+	err := find()
+	if err != nil {
+		return errorwrap.Wrap(err, ErrorDomain)
+	}
+	err = errorwrap.NewError(ErrorDomain)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func parameterReturn(err error) error {
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func comparison(err error) bool {
+	if err == nil || nil != err {
+		return false
+	}
+	if err == io.EOF { // want `compare errors using errorwrap.Is instead of ==`
+		return true
+	}
+	return err != ErrorDomain || errorwrap.Is(err, ErrorDomain) // want `compare errors using errorwrap.Is instead of !=`
+}
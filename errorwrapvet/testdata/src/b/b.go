@@ -0,0 +1,22 @@
+// Package b is a dependency of package a, whose errors cross the layer boundary.
+package b
+
+import (
+	"errors"
+)
+
+var ErrorNotFound = errors.New("not found")
+
+type Repository struct{}
+
+func (Repository) Find() error {
+	return nil
+}
+
+func Find() error {
+	return ErrorNotFound
+}
+
+func Count() (int, error) {
+	return 0, nil
+}
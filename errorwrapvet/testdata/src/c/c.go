@@ -0,0 +1,19 @@
+// Package c does not import errorwrap, so the conventions of errorwrap are not checked.
+package c
+
+import (
+	"b"
+	"io"
+)
+
+func rawReturn() error {
+	err := b.Find()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func comparison(err error) bool {
+	return err == io.EOF || err == b.ErrorNotFound
+}
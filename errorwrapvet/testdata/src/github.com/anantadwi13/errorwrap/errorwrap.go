@@ -0,0 +1,10 @@
+package errorwrap
+
+func New(message string) error                                                { return nil }
+func NewTemplate(message string) error                                        { return nil }
+func NewError(err ...error) error                                             { return nil }
+func NewErrorWithMessage(contextMessage string, err ...error) error           { return nil }
+func AppendInto(errWrapper error, err ...error) error                         { return nil }
+func Wrap(parent error, err ...error) error                                   { return nil }
+func WrapWithMessage(parent error, contextMessage string, err ...error) error { return nil }
+func Is(err error, target error) bool                                         { return false }
@@ -16,21 +16,20 @@ func ExampleNew() {
 	fmt.Println("\nThird")
 	fmt.Printf("%v\n", err)
 	fmt.Println("\nFourth")
-	fmt.Printf("%+v\n", err)
+	fmt.Printf("%+v\n", errorwrap.Normalized(err, errorwrap.GoldenOptions))
 
-	//	Example output:
+	// Output:
+	// First
+	// error message
 	//
-	//	First
-	//	error message
+	// Second
+	// error message
 	//
-	//	Second
-	//	error message
+	// Third
+	// error message
 	//
-	//	Third
-	//	error message
-	//
-	//	Fourth
-	//	error message
+	// Fourth
+	// error message
 }
 
 func ExampleNewError() {
@@ -43,32 +42,27 @@ func ExampleNewError() {
 	fmt.Println("\nThird")
 	fmt.Printf("%v\n", err)
 	fmt.Println("\nFourth")
-	fmt.Printf("%+v\n", err)
+	fmt.Printf("%+v\n", errorwrap.Normalized(err, errorwrap.GoldenOptions))
 
-	//	Example output:
-	//
-	//	First
-	//	 -  error message
-	//	    another error message
+	// Output:
+	// First
+	//  -  error message
+	//     another error message
 	//
-	//	Second
-	//	 -  error message
-	//	    another error message
+	// Second
+	//  -  error message
+	//     another error message
 	//
-	//	Third
-	//	 -  error message
-	//	    another error message
+	// Third
+	//  -  error message
+	//     another error message
 	//
-	//	Fourth
-	//	 -  error message
-	//	    another error message
+	// Fourth
+	//  -  error message
+	//     another error message
 	//
-	//	main.main
-	//		/Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:37
-	//	runtime.main
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
+	// github.com/anantadwi13/errorwrap_test.ExampleNewError
+	// 	github.com/anantadwi13/errorwrap/example_test.go
 }
 
 func ExampleNewErrorWithMessage() {
@@ -81,36 +75,31 @@ func ExampleNewErrorWithMessage() {
 	fmt.Println("\nThird")
 	fmt.Printf("%v\n", err)
 	fmt.Println("\nFourth")
-	fmt.Printf("%+v\n", err)
+	fmt.Printf("%+v\n", errorwrap.Normalized(err, errorwrap.GoldenOptions))
 
-	//	Example output:
-	//
-	//	First
-	//	 -  error message
-	//	    another error message
-	//	    context: context message
-	//
-	//	Second
-	//	 -  error message
-	//	    another error message
-	//	    context: context message
-	//
-	//	Third
-	//	 -  error message
-	//	    another error message
-	//	    context: context message
-	//
-	//	Fourth
-	//	 -  error message
-	//	    another error message
-	//	    context: context message
-	//
-	//	main.main
-	//		/Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:75
-	//	runtime.main
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
+	// Output:
+	// First
+	//  -  error message
+	//     another error message
+	//     context: context message
+	//
+	// Second
+	//  -  error message
+	//     another error message
+	//     context: context message
+	//
+	// Third
+	//  -  error message
+	//     another error message
+	//     context: context message
+	//
+	// Fourth
+	//  -  error message
+	//     another error message
+	//     context: context message
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleNewErrorWithMessage
+	// 	github.com/anantadwi13/errorwrap/example_test.go
 }
 
 func ExampleWrap() {
@@ -127,47 +116,38 @@ func ExampleWrap() {
 	fmt.Println("\nThird")
 	fmt.Printf("%v\n", err)
 	fmt.Println("\nFourth")
-	fmt.Printf("%+v\n", err)
+	fmt.Printf("%+v\n", errorwrap.Normalized(err, errorwrap.GoldenOptions))
 	fmt.Println("\nFifth")
-	fmt.Printf("%+v\n", err2)
+	fmt.Printf("%+v\n", errorwrap.Normalized(err2, errorwrap.GoldenOptions))
 
-	//	Example output:
-	//
-	//	First
-	//	 -  Error B
-	//	    same level with B
-	//
-	//	Second
-	//	 -  Error B
-	//	    same level with B
-	//	 -  Error A
-	//
-	//	Third
-	//	 -  Error B
-	//	    same level with B
-	//
-	//	Fourth
-	//	 -  Error B
-	//	    same level with B
-	//	 -  Error A
-	//
-	//	main.main
-	//		/Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:120
-	//	runtime.main
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
-	//
-	//	Fifth
-	//	 -  Error B
-	//	 -  standard error
-	//
-	//	main.main
-	//		/Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:121
-	//	runtime.main
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
+	// Output:
+	// First
+	//  -  Error B
+	//     same level with B
+	//
+	// Second
+	//  -  Error B
+	//     same level with B
+	//  -  Error A
+	//
+	// Third
+	//  -  Error B
+	//     same level with B
+	//
+	// Fourth
+	//  -  Error B
+	//     same level with B
+	//  -  Error A
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleWrap
+	// 	github.com/anantadwi13/errorwrap/example_test.go
+	//
+	// Fifth
+	//  -  Error B
+	//  -  standard error
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleWrap
+	// 	github.com/anantadwi13/errorwrap/example_test.go
 }
 
 func ExampleWrapWithMessage() {
@@ -184,52 +164,39 @@ func ExampleWrapWithMessage() {
 	fmt.Println("\nThird")
 	fmt.Printf("%v\n", err)
 	fmt.Println("\nFourth")
-	fmt.Printf("%+v\n", err)
+	fmt.Printf("%+v\n", errorwrap.Normalized(err, errorwrap.GoldenOptions))
 	fmt.Println("\nFifth")
-	fmt.Printf("%+v\n", err2)
+	fmt.Printf("%+v\n", errorwrap.Normalized(err2, errorwrap.GoldenOptions))
 
-	//	Example output:
-	//
-	//	First
-	//	 -  Error B
-	//	    same level with B
-	//	    context: context message
-	//
-	//	Second
-	//	 -  Error B
-	//	    same level with B
-	//	    context: context message
-	//	 -  Error A
-	//
-	//	Third
-	//	 -  Error B
-	//	    same level with B
-	//	    context: context message
-	//
-	//	Fourth
-	//	 -  Error B
-	//	    same level with B
-	//	    context: context message
-	//	 -  Error A
-	//
-	//	main.main
-	//		/Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:177
-	//	runtime.main
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
-	//
-	//	Fifth
-	//	 -  Error B
-	//	    context: context message
-	//	 -  standard error
-	//
-	//	main.main
-	//		/Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:178
-	//	runtime.main
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//		/usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
+	// Output:
+	// First
+	//  -  Error B
+	//     context: context message
+	//
+	// Second
+	//  -  Error B
+	//     context: context message
+	//  -  Error A
+	//
+	// Third
+	//  -  Error B
+	//     context: context message
+	//
+	// Fourth
+	//  -  Error B
+	//     context: context message
+	//  -  Error A
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleWrapWithMessage
+	// 	github.com/anantadwi13/errorwrap/example_test.go
+	//
+	// Fifth
+	//  -  Error B
+	//     context: context message
+	//  -  standard error
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleWrapWithMessage
+	// 	github.com/anantadwi13/errorwrap/example_test.go
 }
 
 func ExampleWrapper() {
@@ -255,7 +222,7 @@ func ExampleWrapper() {
 	fmt.Println("wrapErrorA message:")
 	fmt.Printf("%v\n", wrapErrorA)
 	fmt.Println("wrapErrorA message + stack trace:")
-	fmt.Printf("%+v\n", wrapErrorA)
+	fmt.Printf("%+v\n", errorwrap.Normalized(wrapErrorA, errorwrap.GoldenOptions))
 	fmt.Println()
 
 	errWrapper1 := errorwrap.Wrapper(err, ErrorA)
@@ -267,7 +234,7 @@ func ExampleWrapper() {
 	fmt.Println("errWrapper1 message:")
 	fmt.Printf("%v\n", errWrapper1)
 	fmt.Println("errWrapper1 message + stack trace:")
-	fmt.Printf("%+v\n", errWrapper1)
+	fmt.Printf("%+v\n", errorwrap.Normalized(errWrapper1, errorwrap.GoldenOptions))
 	fmt.Println()
 
 	errWrapper2 := errorwrap.Wrapper(err, ErrorB)
@@ -279,81 +246,82 @@ func ExampleWrapper() {
 	fmt.Println("errWrapper2 message:")
 	fmt.Printf("%v\n", errWrapper2)
 	fmt.Println("errWrapper2 message + stack trace:")
-	fmt.Printf("%+v\n", errWrapper2)
+	fmt.Printf("%+v\n", errorwrap.Normalized(errWrapper2, errorwrap.GoldenOptions))
 	fmt.Println()
 
-	//	Example output:
-	//
-	//	wrapper of error definition (should be nil)
-	//	true
-	//
-	//	wrapErrorA
-	//	false
-	//	RootCause:
-	//	<nil>
-	//	ParentError:
-	//	<nil>
-	//	CurrentError:
-	//	["Error A" "error same level with A"]
-	//	wrapErrorA message:
-	//	 -  Error A
-	//	    error same level with A
-	//	wrapErrorA message + stack trace:
-	//	 -  Error A
-	//	    error same level with A
-	//
-	//	main.main
-	//	        /Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:242
-	//	runtime.main
-	//	        /usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//	        /usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
-	//
-	//	errWrapper1
-	//	false
-	//	RootCause:
-	//	<nil>
-	//	ParentError:
-	//	<nil>
-	//	CurrentError:
-	//	["Error A" "error same level with A"]
-	//	errWrapper1 message:
-	//	 -  Error A
-	//	    error same level with A
-	//	errWrapper1 message + stack trace:
-	//	 -  Error A
-	//	    error same level with A
-	//
-	//	main.main
-	//	        /Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:242
-	//	runtime.main
-	//	        /usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//	        /usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
-	//
-	//	errWrapper2
-	//	false
-	//	RootCause:
-	//	 -  Error A
-	//	    error same level with A
-	//	ParentError:
-	//	 -  Error A
-	//	    error same level with A
-	//	CurrentError:
-	//	["Error B"]
-	//	errWrapper2 message:
-	//	 -  Error B
-	//	    context: context message
-	//	errWrapper2 message + stack trace:
-	//	 -  Error B
-	//	    context: context message
-	//	 -  Error A
-	//	    error same level with A
-	//
-	//	main.main
-	//	        /Users/go/src/github.com/anantadwi13/errorwrap/example_test.go:242
-	//	runtime.main
-	//	        /usr/local/Cellar/go/1.17.5/libexec/src/runtime/proc.go:255
-	//	runtime.goexit
-	//	        /usr/local/Cellar/go/1.17.5/libexec/src/runtime/asm_amd64.s:1581
+	// Output:
+	// wrapper of error definition (should be nil)
+	// true
+	//
+	// wrapErrorA
+	// false
+	// RootCause:
+	// <nil>
+	// ParentError:
+	// <nil>
+	// CurrentError:
+	// ["Error A" "error same level with A"]
+	// wrapErrorA message:
+	//  -  Error A
+	//     error same level with A
+	// wrapErrorA message + stack trace:
+	//  -  Error A
+	//     error same level with A
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleWrapper
+	// 	github.com/anantadwi13/errorwrap/example_test.go
+	//
+	// errWrapper1
+	// false
+	// RootCause:
+	// <nil>
+	// ParentError:
+	// <nil>
+	// CurrentError:
+	// ["Error A" "error same level with A"]
+	// errWrapper1 message:
+	//  -  Error A
+	//     error same level with A
+	// errWrapper1 message + stack trace:
+	//  -  Error A
+	//     error same level with A
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleWrapper
+	// 	github.com/anantadwi13/errorwrap/example_test.go
+	//
+	// errWrapper2
+	// false
+	// RootCause:
+	//  -  Error A
+	//     error same level with A
+	// ParentError:
+	//  -  Error A
+	//     error same level with A
+	// CurrentError:
+	// ["Error B"]
+	// errWrapper2 message:
+	//  -  Error B
+	//     context: context message
+	// errWrapper2 message + stack trace:
+	//  -  Error B
+	//     context: context message
+	//  -  Error A
+	//     error same level with A
+	//
+	// github.com/anantadwi13/errorwrap_test.ExampleWrapper
+	// 	github.com/anantadwi13/errorwrap/example_test.go
+}
+
+func ExamplePublicMessage() {
+	ErrorDatabase := errorwrap.New("error database mysql", errorwrap.WithPublicMessage("service is temporarily unavailable"))
+	ErrorUseCase := errorwrap.New("error usecase layer")
+
+	err := errorwrap.Wrap(errorwrap.NewError(ErrorDatabase), ErrorUseCase)
+
+	fmt.Println(errorwrap.PublicMessage(err))
+	fmt.Println(errorwrap.PublicMessage(errors.New("standard error")))
+
+	// Output:
+	// service is temporarily unavailable
+	// an unexpected error occurred
 }
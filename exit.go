@@ -0,0 +1,114 @@
+package errorwrap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// DefaultExitCode is the exit code of an error that has no registered exit code.
+var DefaultExitCode = 1
+
+var (
+	exitMu            sync.RWMutex
+	exitCodes         = map[error]int{}
+	categoryExitCodes = map[Category]int{
+		CategoryInvalidArgument:  64, // EX_USAGE
+		CategoryNotFound:         66, // EX_NOINPUT
+		CategoryUnavailable:      69, // EX_UNAVAILABLE
+		CategoryInternal:         70, // EX_SOFTWARE
+		CategoryTimeout:          75, // EX_TEMPFAIL
+		CategoryPermissionDenied: 77, // EX_NOPERM
+		CategoryUnauthenticated:  77, // EX_NOPERM
+		CategoryCanceled:         130,
+	}
+)
+
+// RegisterExitCode maps an ErrorDefinition onto a process exit code. It takes precedence over the category of the
+// definition.
+func RegisterExitCode(def error, code int) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitCodes[def] = code
+}
+
+// RegisterCategoryExitCode maps a Category onto a process exit code. By default the categories are mapped onto the
+// codes of sysexits.h.
+func RegisterCategoryExitCode(category Category, code int) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	categoryExitCodes[category] = code
+}
+
+// ExitCode returns the process exit code of err. It will find recursively from current level to the root of
+// ErrorWrapper stack and returns the code of the first ErrorDefinition registered using RegisterExitCode. Otherwise
+// the code is mapped from CategoryOf. If err is nil, ExitCode returns 0. If nothing matches, ExitCode returns
+// DefaultExitCode.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	exitMu.RLock()
+	defer exitMu.RUnlock()
+	code := DefaultExitCode
+	found := walkErrors(err, func(err error) bool {
+		if !Hashable(err) {
+			return false
+		}
+		c, ok := exitCodes[err]
+		if ok {
+			code = c
+		}
+		return ok
+	})
+	if found {
+		return code
+	}
+	if c, ok := categoryExitCodes[CategoryOf(err)]; ok {
+		return c
+	}
+	return DefaultExitCode
+}
+
+// Runner runs the main function of a command-line tool. The zero value prints to os.Stderr and exits using os.Exit.
+type Runner struct {
+	// Output is where the error is printed. If nil, os.Stderr is used.
+	Output io.Writer
+	// Verbose prints the error using "%+v", i.e. with the full chain and the stack trace, instead of "%v".
+	Verbose bool
+	// Exit terminates the process. If nil, os.Exit is used.
+	Exit func(code int)
+}
+
+// Run calls fn. If fn returns an error, Run prints it and exits with ExitCode of the error.
+func (r *Runner) Run(fn func() error) {
+	err := fn()
+	if err == nil {
+		return
+	}
+	output, exit := r.Output, r.Exit
+	if output == nil {
+		output = os.Stderr
+	}
+	if exit == nil {
+		exit = os.Exit
+	}
+	if r.Verbose {
+		fmt.Fprintf(output, "%+v\n", err)
+	} else {
+		fmt.Fprintf(output, "%v\n", err)
+	}
+	exit(ExitCode(err))
+}
+
+// Main runs fn as the main function of a command-line tool. If fn returns an error, Main prints it to os.Stderr and
+// exits with ExitCode of the error. The error is printed using "%v", or "%+v" if the DEBUG environment variable is set.
+//
+//	func main() {
+//		errorwrap.Main(run)
+//	}
+func Main(fn func() error) {
+	r := &Runner{Verbose: os.Getenv("DEBUG") != ""}
+	r.Run(fn)
+}
@@ -0,0 +1,129 @@
+package errorwrap
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+var (
+	ErrorExitConfig = New("error invalid config")
+)
+
+// sliceError is an error of an uncomparable type.
+type sliceError []error
+
+func (s sliceError) Error() string {
+	return "slice error"
+}
+
+func TestExitCode(t *testing.T) {
+	RegisterExitCode(ErrorExitConfig, 78)
+	t.Cleanup(func() {
+		exitMu.Lock()
+		delete(exitCodes, ErrorExitConfig)
+		exitMu.Unlock()
+	})
+
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{name: "nil error", args: args{err: nil}, want: 0},
+		{name: "standard error", args: args{err: errors.New("standard error")}, want: DefaultExitCode},
+		{name: "category", args: args{err: Wrap(NewError(ErrorCommonNotFound), ErrorDomain)}, want: 66},
+		{name: "registered definition", args: args{err: ErrorExitConfig}, want: 78},
+		{name: "registered definition wins over category", args: args{err: Wrap(NewError(ErrorExitConfig), ErrorCommonTimeout)}, want: 78},
+		{name: "nested wrapper entry", args: args{err: NewError(Wrap(NewError(ErrorExitConfig), ErrorDomain))}, want: 78},
+		{name: "uncomparable error", args: args{err: sliceError{ErrorExitConfig}}, want: DefaultExitCode},
+		{name: "uncomparable entry", args: args{err: Wrap(NewError(sliceError{}), ErrorExitConfig)}, want: 78},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(tt.args.err))
+		})
+	}
+}
+
+func TestRunner(t *testing.T) {
+	type fields struct {
+		verbose bool
+	}
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		wantExit   int
+		wantOutput string
+	}{
+		{
+			name:     "success",
+			args:     args{err: nil},
+			wantExit: -1,
+		},
+		{
+			name:       "print current level only",
+			args:       args{err: Wrap(NewError(ErrorCommonNotFound), ErrorDomain)},
+			wantExit:   66,
+			wantOutput: " -  error domain layer\n",
+		},
+		{
+			name:       "verbose",
+			fields:     fields{verbose: true},
+			args:       args{err: Wrap(NewError(ErrorCommonNotFound), ErrorDomain)},
+			wantExit:   66,
+			wantOutput: `^ -  error domain layer\n    time: \S+ \(\+\S+\)\n -  error not found\n    time: \S+\n\n`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			exit := -1
+			r := &Runner{
+				Output:  output,
+				Verbose: tt.fields.verbose,
+				Exit:    func(code int) { exit = code },
+			}
+			r.Run(func() error { return tt.args.err })
+
+			assert.Equal(t, tt.wantExit, exit)
+			if tt.fields.verbose {
+				// the creation times vary, so the verbose output is matched as a pattern
+				assert.Regexp(t, tt.wantOutput, output.String())
+				assert.Contains(t, output.String(), "exit_test.go")
+				return
+			}
+			assert.Equal(t, tt.wantOutput, output.String())
+		})
+	}
+}
+
+func TestRunnerZeroValue(t *testing.T) {
+	if os.Getenv("ERRORWRAP_TEST_RUNNER") == "1" {
+		(&Runner{}).Run(func() error { return Wrap(NewError(ErrorCommonNotFound), ErrorDomain) })
+		return
+	}
+
+	// os.Exit terminates the process, so the zero value Runner is run by a child test process
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunnerZeroValue$")
+	cmd.Env = append(os.Environ(), "ERRORWRAP_TEST_RUNNER=1")
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if assert.True(t, errors.As(err, &exitErr)) {
+		assert.Equal(t, 66, exitErr.ExitCode())
+	}
+	assert.Equal(t, " -  error domain layer\n", stderr.String())
+}
@@ -0,0 +1,97 @@
+package errorwrap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fields is structured metadata attached to an error.
+type Fields map[string]interface{}
+
+type fielder interface {
+	Fields() Fields
+}
+
+// AppendFields appends fields into the current level of errWrapper. Existing keys are overwritten. It will return the
+// same errWrapper instance or new instance if errWrapper is not an ErrorWrapper. If errWrapper is nil then
+// AppendFields returns nil.
+func AppendFields(errWrapper error, fields Fields) error {
+	if errWrapper == nil {
+		return nil
+	}
+	ew, ok := errWrapper.(*errorWrapper)
+	if !ok || ew == nil {
+		ew = newRootWrapper(callStack(), errWrapper)
+		if ew == nil {
+			return nil
+		}
+	}
+	if ew.fields == nil {
+		ew.fields = Fields{}
+	}
+	for k, v := range fields {
+		ew.fields[k] = v
+	}
+	return ew
+}
+
+// FieldsOf returns all fields of err. It collects the fields of every level from the root of ErrorWrapper stack to
+// the current level, so the fields of the upper levels overwrite the fields of the lower levels. The values of the
+// keys registered using RedactFields are masked.
+func FieldsOf(err error) Fields {
+	var levels []error
+	for ; err != nil; err = Unwrap(err) {
+		levels = append(levels, err)
+	}
+
+	fields := Fields{}
+	for i := len(levels) - 1; i >= 0; i-- {
+		if ew, ok := levels[i].(ErrorWrapper); ok {
+			for _, curErr := range ew.CurrentError() {
+				if f, ok := curErr.(fielder); ok {
+					mergeFields(fields, f.Fields())
+				}
+			}
+		}
+		if f, ok := levels[i].(fielder); ok {
+			mergeFields(fields, f.Fields())
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return redactFields(fields, true)
+}
+
+func mergeFields(dst Fields, src Fields) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+func redactFields(fields Fields, redact bool) Fields {
+	if len(fields) == 0 {
+		return nil
+	}
+	redacted := make(Fields, len(fields))
+	for k, v := range fields {
+		redacted[k] = fieldValue(k, v, redact)
+	}
+	return redacted
+}
+
+func formatFields(fields Fields, redact bool) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	str := make([]string, 0, len(keys))
+	for _, k := range keys {
+		str = append(str, fmt.Sprintf("%s=%v", k, fieldValue(k, fields[k], redact)))
+	}
+	return strings.Join(str, " ")
+}
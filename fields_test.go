@@ -0,0 +1,74 @@
+package errorwrap
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAppendFields(t *testing.T) {
+	type args struct {
+		errWrapper error
+		fields     Fields
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantNil    bool
+		wantFields Fields
+	}{
+		{
+			name:    "expect nil",
+			args:    args{errWrapper: nil, fields: Fields{"id": 1}},
+			wantNil: true,
+		},
+		{
+			name:       "error definition",
+			args:       args{errWrapper: ErrorTestA, fields: Fields{"id": 1}},
+			wantFields: Fields{"id": 1},
+		},
+		{
+			name:       "error wrapper",
+			args:       args{errWrapper: appLayer(), fields: Fields{"id": 1, "name": "test"}},
+			wantFields: Fields{"id": 1, "name": "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AppendFields(tt.args.errWrapper, tt.args.fields)
+			if tt.wantNil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, tt.wantFields, got.(fielder).Fields())
+			assert.True(t, Is(got, tt.args.errWrapper))
+		})
+	}
+}
+
+func TestFieldsOf(t *testing.T) {
+	root := AppendFields(NewError(ErrorInfraDatabase), Fields{"table": "users", "id": 1})
+	domain := AppendFields(Wrap(root, ErrorDomain), Fields{"id": 2})
+	usecase := Wrap(domain, ErrorUseCase, ErrorTemplateUserNotFound.With(Fields{"user": "john"}))
+
+	assert.Nil(t, FieldsOf(nil))
+	assert.Nil(t, FieldsOf(appLayer()))
+	assert.Equal(t, Fields{"table": "users", "id": 1}, FieldsOf(root))
+	assert.Equal(t, Fields{"table": "users", "id": 2}, FieldsOf(domain))
+	assert.Equal(t, Fields{"table": "users", "id": 2, "user": "john"}, FieldsOf(usecase))
+	assert.Equal(t, Fields{"user": "john"}, FieldsOf(ErrorTemplateUserNotFound.With(Fields{"user": "john"})))
+}
+
+func TestFieldsFormat(t *testing.T) {
+	RedactFields("password")
+	t.Cleanup(func() {
+		redactionMu.Lock()
+		delete(redactionFields, "password")
+		redactionMu.Unlock()
+	})
+
+	err := AppendFields(NewError(ErrorInfraDatabase), Fields{"user": "john", "password": "secret"})
+	assert.Equal(t, " -  error infra layer", err.Error())
+	assert.Equal(t, " -  error infra layer\n    fields: password=[REDACTED] user=john", fmt.Sprintf("%+s", err))
+	assert.Equal(t, " -  error infra layer\n    fields: password=secret user=john", fmt.Sprintf("%+s", Unsafe(err)))
+}
@@ -0,0 +1,126 @@
+package errorwrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FingerprintStrategy returns the components identifying err, which are hashed by Fingerprint. Two errors having the
+// same components are considered equivalent failures.
+type FingerprintStrategy func(err error) []string
+
+var (
+	// FingerprintDefinitions identifies an error by the definitions placed in each level.
+	FingerprintDefinitions FingerprintStrategy = func(err error) []string {
+		return fingerprintLevels(err, false)
+	}
+	// FingerprintWrapSites identifies an error by the definitions placed in each level and the function where each
+	// level was created or wrapped. It is the default strategy.
+	FingerprintWrapSites FingerprintStrategy = func(err error) []string {
+		return fingerprintLevels(err, true)
+	}
+	// FingerprintRootFrame identifies an error by the definitions placed in each level and the function where the
+	// root cause was created.
+	FingerprintRootFrame FingerprintStrategy = func(err error) []string {
+		components := fingerprintLevels(err, false)
+		if ew, ok := err.(*errorWrapper); ok && ew != nil {
+			root := ew
+			if rc, ok := ew.rootCause.(*errorWrapper); ok && rc != nil {
+				root = rc
+			}
+			if fn := wrapSite(root); fn != "" {
+				components = append(components, "root "+fn)
+			}
+		}
+		return components
+	}
+)
+
+var (
+	fingerprintMu       sync.RWMutex
+	fingerprintStrategy = FingerprintWrapSites
+)
+
+// SetFingerprintStrategy replaces the strategy used by Fingerprint. If strategy is nil, FingerprintWrapSites is used.
+func SetFingerprintStrategy(strategy FingerprintStrategy) {
+	if strategy == nil {
+		strategy = FingerprintWrapSites
+	}
+	fingerprintMu.Lock()
+	defer fingerprintMu.Unlock()
+	fingerprintStrategy = strategy
+}
+
+// Fingerprint returns a stable hash grouping equivalent failures, computed using the strategy set by
+// SetFingerprintStrategy. The context messages, fields, messages of foreign errors and line numbers are not taken into
+// account, so the same failure happening with different inputs or after unrelated code changes has the same
+// fingerprint. If err is nil then Fingerprint returns an empty string.
+func Fingerprint(err error) string {
+	fingerprintMu.RLock()
+	strategy := fingerprintStrategy
+	fingerprintMu.RUnlock()
+	return FingerprintWith(err, strategy)
+}
+
+// FingerprintWith returns the fingerprint of err computed using strategy.
+func FingerprintWith(err error, strategy FingerprintStrategy) string {
+	if err == nil || strategy == nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, c := range strategy(err) {
+		h.Write([]byte(c))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// fingerprintLevels returns one component for each level of err, from current level to the root of ErrorWrapper stack.
+func fingerprintLevels(err error, wrapSites bool) []string {
+	var components []string
+	for ; err != nil; err = Unwrap(err) {
+		ew, ok := err.(*errorWrapper)
+		if !ok || ew == nil {
+			components = append(components, errorIdentity(err))
+			break
+		}
+
+		ids := make([]string, 0, len(ew.errors))
+		for _, curErr := range ew.errors {
+			walkErrors(curErr, func(err error) bool {
+				ids = append(ids, errorIdentity(err))
+				return false
+			})
+		}
+		component := strings.Join(ids, "|")
+		if wrapSites {
+			component += " at " + wrapSite(ew)
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// errorIdentity returns the code of the definition of err, or its message if it has no code. Instantiated Templates
+// are identified by their Template, so the arguments do not matter. A foreign error is identified by its type only,
+// since its message often carries dynamic values such as ids.
+func errorIdentity(err error) string {
+	if def := definitionOf(err); def != nil {
+		if def.code != "" {
+			return def.code
+		}
+		return def.msg
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// wrapSite returns the function name of the first frame of ew.
+func wrapSite(ew *errorWrapper) string {
+	if ew.stack == nil || len(*ew.stack) == 0 {
+		return ""
+	}
+	return Frame((*ew.stack)[0]).functionName()
+}
@@ -0,0 +1,132 @@
+package errorwrap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func fingerprintSiteA(id int) error {
+	return WrapWithMessage(infraDbLayer(REDIS), fmt.Sprintf("find user %d", id), ErrorDomain)
+}
+
+func fingerprintSiteB(id int) error {
+	return WrapWithMessage(infraDbLayer(REDIS), fmt.Sprintf("find order %d", id), ErrorDomain)
+}
+
+func TestFingerprintWith(t *testing.T) {
+	userNotFound := NewTemplate("user {id} not found", WithCode("USER_NOT_FOUND"))
+
+	type args struct {
+		a        error
+		b        error
+		strategy FingerprintStrategy
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantEqual bool
+	}{
+		{
+			name:      "same site, different context",
+			args:      args{a: fingerprintSiteA(1), b: fingerprintSiteA(2), strategy: FingerprintWrapSites},
+			wantEqual: true,
+		},
+		{
+			name:      "different sites, wrap sites",
+			args:      args{a: fingerprintSiteA(1), b: fingerprintSiteB(1), strategy: FingerprintWrapSites},
+			wantEqual: false,
+		},
+		{
+			name:      "different sites, definitions",
+			args:      args{a: fingerprintSiteA(1), b: fingerprintSiteB(1), strategy: FingerprintDefinitions},
+			wantEqual: true,
+		},
+		{
+			name:      "different sites, same root frame",
+			args:      args{a: fingerprintSiteA(1), b: fingerprintSiteB(1), strategy: FingerprintRootFrame},
+			wantEqual: true,
+		},
+		{
+			name: "different root frames",
+			args: args{
+				a: fingerprintSiteA(1),
+				b: WrapWithMessage(NewErrorWithMessage("redis not found", ErrorInfraDatabase, ErrorCommonNotFound,
+					ErrorRedisDb), "find user 1", ErrorDomain),
+				strategy: FingerprintRootFrame,
+			},
+			wantEqual: false,
+		},
+		{
+			name:      "different definitions",
+			args:      args{a: domainLayer(MYSQL), b: domainLayer(REDIS), strategy: FingerprintDefinitions},
+			wantEqual: false,
+		},
+		{
+			name: "template arguments",
+			args: args{
+				a:        NewError(userNotFound.With(Fields{"id": 1})),
+				b:        NewError(userNotFound.With(Fields{"id": 2})),
+				strategy: FingerprintDefinitions,
+			},
+			wantEqual: true,
+		},
+		{
+			name: "foreign errors with different ids",
+			args: args{
+				a:        fmt.Errorf("user %d not found", 1),
+				b:        fmt.Errorf("user %d not found", 2),
+				strategy: FingerprintDefinitions,
+			},
+			wantEqual: true,
+		},
+		{
+			name: "wrapped foreign errors with different ids",
+			args: args{
+				a:        Wrap(fmt.Errorf("user %d not found", 1), ErrorDomain),
+				b:        Wrap(fmt.Errorf("user %d not found", 2), ErrorDomain),
+				strategy: FingerprintWrapSites,
+			},
+			wantEqual: true,
+		},
+		{
+			name:      "foreign errors of different types",
+			args:      args{a: errors.New("error a"), b: fmt.Errorf("error a: %w", io.EOF), strategy: FingerprintDefinitions},
+			wantEqual: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := FingerprintWith(tt.args.a, tt.args.strategy)
+			b := FingerprintWith(tt.args.b, tt.args.strategy)
+			assert.Len(t, a, 32)
+			if tt.wantEqual {
+				assert.Equal(t, a, b)
+			} else {
+				assert.NotEqual(t, a, b)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	defer SetFingerprintStrategy(nil)
+
+	var errs []error
+	for i := 0; i < 2; i++ {
+		errs = append(errs, fingerprintSiteA(i))
+	}
+	errs = append(errs, fingerprintSiteA(2))
+
+	assert.Equal(t, "", Fingerprint(nil))
+	assert.Equal(t, Fingerprint(errs[0]), Fingerprint(errs[1]))
+	assert.Equal(t, Fingerprint(errs[0]), Fingerprint(errs[2]))
+	assert.Equal(t, FingerprintWith(errs[0], FingerprintWrapSites), Fingerprint(errs[0]))
+
+	SetFingerprintStrategy(func(err error) []string {
+		return []string{"constant"}
+	})
+	assert.Equal(t, Fingerprint(errs[0]), Fingerprint(appLayer()))
+}
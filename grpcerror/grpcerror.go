@@ -0,0 +1,280 @@
+// Package grpcerror converts errorwrap errors into gRPC statuses and back.
+//
+// On the server side an ErrorWrapper is converted into a status whose code is mapped from the definitions or the
+// category of the chain, whose message is errorwrap.PublicMessage, and whose details carry the codes of the chain as
+// errdetails.ErrorInfo. If nothing can be mapped, the code and the message of a gRPC status carried by the chain, e.g.
+// the error of an outgoing call, are used instead. On the client side the details are resolved back into the definitions registered using
+// errorwrap.RegisterDefinition, so errorwrap.Is works across RPC boundaries.
+package grpcerror
+
+import (
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Domain is the errdetails.ErrorInfo domain of the details created by ToStatus.
+const Domain = "errorwrap"
+
+const metadataLevel = "level"
+
+var (
+	mu              sync.RWMutex
+	definitionCodes = map[error]codes.Code{}
+	categoryCodes   = map[errorwrap.Category]codes.Code{
+		errorwrap.CategoryInvalidArgument:  codes.InvalidArgument,
+		errorwrap.CategoryNotFound:         codes.NotFound,
+		errorwrap.CategoryAlreadyExists:    codes.AlreadyExists,
+		errorwrap.CategoryPermissionDenied: codes.PermissionDenied,
+		errorwrap.CategoryUnauthenticated:  codes.Unauthenticated,
+		errorwrap.CategoryTimeout:          codes.DeadlineExceeded,
+		errorwrap.CategoryCanceled:         codes.Canceled,
+		errorwrap.CategoryUnavailable:      codes.Unavailable,
+		errorwrap.CategoryInternal:         codes.Internal,
+	}
+)
+
+// RegisterCode maps an ErrorDefinition onto a gRPC code. It takes precedence over the category of the definition.
+func RegisterCode(def error, code codes.Code) {
+	mu.Lock()
+	defer mu.Unlock()
+	definitionCodes[def] = code
+}
+
+// RegisterCategoryCode maps a Category onto a gRPC code.
+func RegisterCategoryCode(category errorwrap.Category, code codes.Code) {
+	mu.Lock()
+	defer mu.Unlock()
+	categoryCodes[category] = code
+}
+
+// Code returns the gRPC code of err. It will find recursively from current level to the root of ErrorWrapper stack and
+// returns the code of the first ErrorDefinition registered using RegisterCode. Otherwise the code is mapped from
+// errorwrap.CategoryOf. If nothing matches, the code of the gRPC status carried by an error of the chain is returned,
+// e.g. the status of an error returned by a gRPC call. If err is nil, Code returns codes.OK. If there is no status
+// either, Code returns codes.Unknown.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if code, ok := mappedCode(err); ok {
+		return code
+	}
+	if st, ok := chainStatus(err); ok {
+		return st.Code()
+	}
+	return codes.Unknown
+}
+
+// mappedCode returns the code of err mapped from its definitions or its category.
+func mappedCode(err error) (codes.Code, bool) {
+	if code, ok := definitionCode(err); ok {
+		return code, true
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	code, ok := categoryCodes[errorwrap.CategoryOf(err)]
+	return code, ok
+}
+
+func definitionCode(err error) (codes.Code, bool) {
+	for ; err != nil; err = errorwrap.Unwrap(err) {
+		ew, ok := err.(errorwrap.ErrorWrapper)
+		if !ok {
+			if code, ok := registeredCode(err); ok {
+				return code, true
+			}
+			continue
+		}
+		for _, curErr := range ew.CurrentError() {
+			if code, ok := definitionCode(curErr); ok {
+				return code, true
+			}
+		}
+	}
+	return codes.Unknown, false
+}
+
+// registeredCode returns the code registered for err using RegisterCode. Errors of uncomparable types, e.g.
+// slice-backed multi-errors, cannot be registered and are skipped since hashing them panics.
+func registeredCode(err error) (codes.Code, bool) {
+	if !errorwrap.Hashable(err) {
+		return codes.Unknown, false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	code, ok := definitionCodes[err]
+	return code, ok
+}
+
+// ToStatus converts err into a gRPC status. If err is nil, ToStatus returns nil. If err is neither an ErrorWrapper nor
+// an ErrorDefinition but carries a gRPC status, that status is returned as is. If the code of err cannot be mapped from
+// its definitions or its category but an error of the chain carries a gRPC status, the code and the message of that
+// status are used, so the errors of a gRPC call can be returned by a proxy.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if !isErrorwrap(err) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+	}
+
+	code, ok := mappedCode(err)
+	msg := errorwrap.PublicMessage(err)
+	if !ok {
+		code = codes.Unknown
+		if remote, ok := chainStatus(err); ok {
+			code, msg = remote.Code(), remote.Message()
+		}
+	}
+	st := status.New(code, msg)
+	var details []protoadapt.MessageV1
+	for level, levelCodes := range chainCodes(err) {
+		for _, code := range levelCodes {
+			details = append(details, &errdetails.ErrorInfo{
+				Reason:   code,
+				Domain:   Domain,
+				Metadata: map[string]string{metadataLevel: strconv.Itoa(level)},
+			})
+		}
+	}
+	if len(details) == 0 {
+		return st
+	}
+	withDetails, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromStatus converts a gRPC status back into an ErrorWrapper. The root level contains st.Err(), so status.Code keeps
+// working, followed by the registered definitions of the deepest level. Each upper level contains the registered
+// definitions of that level. Codes that are not registered are skipped. If st is nil or OK, FromStatus returns nil.
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	levels := map[int][]error{}
+	var depths []int
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != Domain {
+			continue
+		}
+		def, ok := errorwrap.DefinitionByCode(info.GetReason())
+		if !ok {
+			continue
+		}
+		level, _ := strconv.Atoi(info.GetMetadata()[metadataLevel])
+		if _, ok := levels[level]; !ok {
+			depths = append(depths, level)
+		}
+		levels[level] = append(levels[level], def)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(depths)))
+
+	var err error
+	for i, depth := range depths {
+		if i == 0 {
+			err = errorwrap.NewError(append([]error{st.Err()}, levels[depth]...)...)
+			continue
+		}
+		err = errorwrap.Wrap(err, levels[depth]...)
+	}
+	if err == nil {
+		return errorwrap.NewError(st.Err())
+	}
+	return err
+}
+
+// FromError converts err returned by a gRPC call back into an ErrorWrapper using FromStatus. Errors without a gRPC
+// status are returned as is.
+func FromError(err error) error {
+	if err == nil || isErrorwrap(err) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
+
+// chainCodes returns the definition codes of each level of err, from current level to the root.
+func chainCodes(err error) [][]string {
+	var levels [][]string
+	for ; err != nil; err = errorwrap.Unwrap(err) {
+		ew, ok := err.(errorwrap.ErrorWrapper)
+		if !ok {
+			if code := errorwrap.Code(err); code != "" {
+				levels = append(levels, []string{code})
+			}
+			continue
+		}
+		var levelCodes []string
+		for _, curErr := range ew.CurrentError() {
+			levelCodes = append(levelCodes, entryCodes(curErr)...)
+		}
+		levels = append(levels, levelCodes)
+	}
+	return levels
+}
+
+// entryCodes returns the definition codes of a CurrentError entry. The codes of a nested ErrorWrapper are flattened.
+func entryCodes(err error) []string {
+	if _, ok := err.(errorwrap.ErrorWrapper); !ok {
+		if code := errorwrap.Code(err); code != "" {
+			return []string{code}
+		}
+		return nil
+	}
+	var result []string
+	for _, levelCodes := range chainCodes(err) {
+		result = append(result, levelCodes...)
+	}
+	return result
+}
+
+// grpcStatus is implemented by the errors carrying a gRPC status, e.g. the errors returned by a gRPC call.
+type grpcStatus interface {
+	GRPCStatus() *status.Status
+}
+
+// chainStatus returns the gRPC status carried by the first error of err that has one, from current level to the root
+// of ErrorWrapper stack.
+func chainStatus(err error) (*status.Status, bool) {
+	for ; err != nil; err = errorwrap.Unwrap(err) {
+		ew, ok := err.(errorwrap.ErrorWrapper)
+		if !ok {
+			var carrier grpcStatus
+			if errors.As(err, &carrier) && carrier.GRPCStatus() != nil {
+				return carrier.GRPCStatus(), true
+			}
+			continue
+		}
+		for _, curErr := range ew.CurrentError() {
+			if st, ok := chainStatus(curErr); ok {
+				return st, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func isErrorwrap(err error) bool {
+	switch err.(type) {
+	case errorwrap.ErrorWrapper, errorwrap.ErrorDefinition:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package grpcerror
+
+import (
+	"errors"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+var (
+	ErrorUserNotFound = errorwrap.New("user 42 not found in mysql", errorwrap.WithCode("TEST_USER_NOT_FOUND"),
+		errorwrap.WithCategory(errorwrap.CategoryNotFound), errorwrap.WithPublicMessage("user not found"))
+	ErrorQuota    = errorwrap.New("quota exceeded", errorwrap.WithCode("TEST_QUOTA"))
+	ErrorDomain   = errorwrap.New("error domain layer", errorwrap.WithCode("TEST_DOMAIN"))
+	ErrorUseCase  = errorwrap.New("error usecase layer", errorwrap.WithCode("TEST_USECASE"))
+	ErrorInternal = errorwrap.New("error internal")
+)
+
+// sliceError is an error of an uncomparable type.
+type sliceError []error
+
+func (s sliceError) Error() string {
+	return "slice error"
+}
+
+func init() {
+	errorwrap.RegisterDefinition(ErrorUserNotFound, ErrorQuota, ErrorDomain, ErrorUseCase)
+	RegisterCode(ErrorQuota, codes.ResourceExhausted)
+}
+
+func TestCode(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want codes.Code
+	}{
+		{name: "nil error", args: args{err: nil}, want: codes.OK},
+		{name: "standard error", args: args{err: errors.New("standard error")}, want: codes.Unknown},
+		{name: "category", args: args{err: errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorDomain)}, want: codes.NotFound},
+		{name: "registered definition", args: args{err: errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorQuota)}, want: codes.ResourceExhausted},
+		{name: "registered definition in root level", args: args{err: errorwrap.Wrap(errorwrap.NewError(ErrorQuota), ErrorUserNotFound)}, want: codes.ResourceExhausted},
+		{name: "without category", args: args{err: errorwrap.NewError(ErrorInternal)}, want: codes.Unknown},
+		{name: "uncomparable error", args: args{err: sliceError{ErrorQuota}}, want: codes.Unknown},
+		{name: "uncomparable entry", args: args{err: errorwrap.Wrap(errorwrap.NewError(sliceError{}), ErrorQuota)}, want: codes.ResourceExhausted},
+		{name: "status of client error", args: args{err: errorwrap.Wrap(FromStatus(status.New(codes.NotFound, "missing thing")), ErrorDomain)}, want: codes.NotFound},
+		{name: "status of wrapped client error", args: args{err: errorwrap.NewError(fmt.Errorf("call: %w", status.Error(codes.Aborted, "aborted")))}, want: codes.Aborted},
+		{name: "category over client status", args: args{err: errorwrap.Wrap(FromStatus(status.New(codes.Aborted, "aborted")), ErrorUserNotFound)}, want: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Code(tt.args.err))
+		})
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	assert.Nil(t, ToStatus(nil))
+
+	passthrough := status.Error(codes.Aborted, "aborted")
+	assert.Equal(t, codes.Aborted, ToStatus(passthrough).Code())
+
+	err := errorwrap.Wrap(errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound, ErrorInternal), ErrorDomain), ErrorUseCase)
+	st := ToStatus(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "user not found", st.Message())
+
+	var reasons, levels []string
+	for _, detail := range st.Details() {
+		info := detail.(*errdetails.ErrorInfo)
+		assert.Equal(t, Domain, info.GetDomain())
+		reasons = append(reasons, info.GetReason())
+		levels = append(levels, info.GetMetadata()["level"])
+	}
+	assert.Equal(t, []string{"TEST_USECASE", "TEST_DOMAIN", "TEST_USER_NOT_FOUND"}, reasons)
+	assert.Equal(t, []string{"0", "1", "2"}, levels)
+
+	proxied := errorwrap.Wrap(FromStatus(status.New(codes.NotFound, "missing thing")), ErrorDomain)
+	st = ToStatus(proxied)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "missing thing", st.Message())
+
+	st = ToStatus(errors.New("mysql: connection refused"))
+	assert.Equal(t, codes.Unknown, st.Code())
+	assert.Equal(t, errorwrap.GenericPublicMessage, st.Message())
+	assert.Empty(t, st.Details())
+}
+
+func TestFromStatus(t *testing.T) {
+	assert.Nil(t, FromStatus(nil))
+	assert.Nil(t, FromStatus(status.New(codes.OK, "")))
+
+	original := errorwrap.Wrap(errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorDomain), ErrorUseCase, ErrorQuota)
+	err := FromStatus(ToStatus(original))
+
+	assert.True(t, errorwrap.IsExact(err, ErrorUseCase))
+	assert.True(t, errorwrap.IsExact(err, ErrorQuota))
+	assert.True(t, errorwrap.Is(err, ErrorDomain))
+	assert.True(t, errorwrap.Is(err, ErrorUserNotFound))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, codes.ResourceExhausted, Code(err))
+
+	wrapper := errorwrap.Wrapper(err, ErrorDomain)
+	assert.Len(t, wrapper.CurrentError(), 1)
+	assert.Nil(t, wrapper.ParentError().ParentError())
+
+	plain := FromStatus(status.New(codes.Unavailable, "unavailable"))
+	assert.Equal(t, codes.Unavailable, status.Code(plain))
+	assert.False(t, errorwrap.Is(plain, ErrorDomain))
+}
+
+func TestFromError(t *testing.T) {
+	assert.Nil(t, FromError(nil))
+	std := errors.New("standard error")
+	assert.Equal(t, std, FromError(std))
+	wrapped := errorwrap.NewError(ErrorDomain)
+	assert.Equal(t, wrapped, FromError(wrapped))
+	assert.True(t, errorwrap.Is(FromError(ToStatus(wrapped).Err()), ErrorDomain))
+}
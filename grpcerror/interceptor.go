@@ -0,0 +1,70 @@
+package grpcerror
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"io"
+)
+
+// UnaryServerInterceptor converts the errors returned by unary handlers using ToStatus.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, statusError(err)
+	}
+}
+
+// StreamServerInterceptor converts the errors returned by stream handlers using ToStatus.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return statusError(handler(srv, ss))
+	}
+}
+
+// UnaryClientInterceptor converts the errors returned by unary calls using FromError.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor converts the errors returned by stream calls, except io.EOF, using FromError.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromError(err)
+		}
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return streamError(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return streamError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return streamError(s.ClientStream.CloseSend())
+}
+
+func streamError(err error) error {
+	if err == io.EOF {
+		return err
+	}
+	return FromError(err)
+}
+
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ToStatus(err).Err()
+}
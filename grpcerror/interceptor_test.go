@@ -0,0 +1,92 @@
+package grpcerror
+
+import (
+	"context"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func newTestClient(t *testing.T, err error) healthpb.HealthClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(server, &healthServer{err: err})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, dialErr := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if dialErr != nil {
+		t.Fatal(dialErr)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	client := newTestClient(t, errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorDomain))
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+	assert.True(t, errorwrap.Is(err, ErrorUserNotFound))
+	assert.True(t, errorwrap.IsExact(err, ErrorDomain))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "user not found", errorwrap.PublicMessage(err))
+	assert.NotContains(t, err.Error(), "mysql")
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	client := newTestClient(t, errorwrap.Wrap(errorwrap.NewError(ErrorDomain), ErrorQuota))
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	_, err = stream.Recv()
+	assert.True(t, errorwrap.IsExact(err, ErrorQuota))
+	assert.True(t, errorwrap.Is(err, ErrorDomain))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestInterceptorPassthroughStatus(t *testing.T) {
+	client := newTestClient(t, status.Error(codes.Aborted, "aborted"))
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Contains(t, err.Error(), "aborted")
+}
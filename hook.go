@@ -0,0 +1,37 @@
+package errorwrap
+
+import (
+	"sync"
+)
+
+// CreationHook is called with the ErrorWrapper returned by NewError, NewErrorWithMessage, AppendInto, Wrap or
+// WrapWithMessage when the call creates a new root cause, i.e. once for each ErrorWrapper stack. It is also called
+// when a function such as AppendFields, SetLayer or Classify places a foreign error into a new ErrorWrapper. The hook is called
+// synchronously, so it must be fast and must not modify err.
+type CreationHook func(err error)
+
+var (
+	creationHookMu sync.RWMutex
+	creationHooks  []CreationHook
+)
+
+// RegisterCreationHook registers hooks called when a new ErrorWrapper stack is created.
+func RegisterCreationHook(hook ...CreationHook) {
+	creationHookMu.Lock()
+	defer creationHookMu.Unlock()
+	for _, h := range hook {
+		if h == nil {
+			continue
+		}
+		creationHooks = append(creationHooks, h)
+	}
+}
+
+func notifyCreated(err *errorWrapper) {
+	creationHookMu.RLock()
+	hooks := creationHooks
+	creationHookMu.RUnlock()
+	for _, h := range hooks {
+		h(err)
+	}
+}
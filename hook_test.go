@@ -0,0 +1,62 @@
+package errorwrap
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	// hookEnabled and hookCreated are used by the creation hook registered for tests, since hooks cannot be
+	// unregistered.
+	hookEnabled bool
+	hookCreated []error
+)
+
+func init() {
+	RegisterCreationHook(func(err error) {
+		if hookEnabled {
+			hookCreated = append(hookCreated, err)
+		}
+	})
+}
+
+func TestRegisterCreationHook(t *testing.T) {
+	type args struct {
+		create func() error
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantCalls int
+	}{
+		{name: "NewError", args: args{create: func() error { return NewError(ErrorTestA) }}, wantCalls: 1},
+		{name: "NewErrorWithMessage", args: args{create: func() error { return NewErrorWithMessage("msg", ErrorTestA) }}, wantCalls: 1},
+		{name: "AppendInto new instance", args: args{create: func() error { return AppendInto(nil, ErrorTestA) }}, wantCalls: 1},
+		{name: "Wrap foreign error", args: args{create: func() error { return Wrap(ErrorTestB, ErrorDomain) }}, wantCalls: 1},
+		{name: "Wrap foreign error only", args: args{create: func() error { return Wrap(ErrorTestB) }}, wantCalls: 1},
+		{name: "Wrap nil parent", args: args{create: func() error { return WrapWithMessage(nil, "msg", ErrorDomain) }}, wantCalls: 1},
+		{name: "AppendFields foreign error", args: args{create: func() error { return AppendFields(ErrorTestB, Fields{"id": 1}) }}, wantCalls: 1},
+		{name: "AppendDetails foreign error", args: args{create: func() error { return AppendDetails(ErrorTestB, "detail") }}, wantCalls: 1},
+		{name: "SetLayer foreign error", args: args{create: func() error { return SetLayer(ErrorTestB, "infra") }}, wantCalls: 1},
+		{name: "MarkRetryable foreign error", args: args{create: func() error { return MarkRetryable(ErrorTestB, 0) }}, wantCalls: 1},
+		{name: "Classify foreign error", args: args{create: func() error { return Classify(errors.New("standard")) }}, wantCalls: 1},
+		{name: "AppendFields existing wrapper", args: args{create: func() error { return AppendFields(appLayer(), Fields{"id": 1}) }}, wantCalls: 1},
+		{name: "nil error", args: args{create: func() error { return NewError(nil) }}, wantCalls: 0},
+		{name: "whole stack", args: args{create: func() error { return appLayer() }}, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hookEnabled, hookCreated = true, nil
+			defer func() {
+				hookEnabled, hookCreated = false, nil
+			}()
+
+			err := tt.args.create()
+			if assert.Len(t, hookCreated, tt.wantCalls) && tt.wantCalls > 0 {
+				// the hook receives the returned error, which is the root cause when it is wrapped later on
+				assert.True(t, hookCreated[0] == err || hookCreated[0] == err.(ErrorWrapper).RootCause())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package errorwrap
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type jsonLevel struct {
+	Errors  []jsonError   `json:"errors"`
+	Context string        `json:"context,omitempty"`
+	Layer   string        `json:"layer,omitempty"`
+	Time    *time.Time    `json:"time,omitempty"`
+	Elapsed string        `json:"elapsed,omitempty"`
+	Fields  Fields        `json:"fields,omitempty"`
+	Details []interface{} `json:"details,omitempty"`
+	Stack   StackTrace    `json:"stack,omitempty"`
+}
+
+type jsonError struct {
+	Message string `json:"message"`
+	Fields  Fields `json:"fields,omitempty"`
+}
+
+// MarshalJSON encodes every level of the ErrorWrapper stack, from the current level to the root. Sensitive messages
+// and fields are masked, use Unsafe to encode them as is.
+func (e *errorWrapper) MarshalJSON() ([]byte, error) {
+	return e.marshalJSON(true)
+}
+
+func (e *errorWrapper) marshalJSON(redact bool) ([]byte, error) {
+	var levels []jsonLevel
+	var err error = e
+	for err != nil {
+		ew, ok := err.(*errorWrapper)
+		if !ok || ew == nil {
+			levels = append(levels, jsonLevel{Errors: []jsonError{newJSONError(err, redact)}})
+			break
+		}
+
+		level := jsonLevel{
+			Context: ew.contextMsg,
+			Layer:   ew.Layer(),
+			Fields:  redactFields(ew.fields, redact),
+			Details: ew.details,
+		}
+		if level.Context != "" {
+			level.Context = contextMessage(level.Context, redact)
+		}
+		if !ew.time.IsZero() {
+			t := ew.time
+			level.Time = &t
+			if elapsed, ok := Elapsed(ew); ok && ew.root() != ew {
+				level.Elapsed = elapsed.String()
+			}
+		}
+		if ew.stack != nil {
+			level.Stack = ew.StackTrace()
+		}
+		for _, curErr := range ew.errors {
+			level.Errors = append(level.Errors, newJSONError(curErr, redact))
+		}
+		levels = append(levels, level)
+		err = ew.parentError
+	}
+
+	return json.Marshal(struct {
+		Levels []jsonLevel `json:"levels"`
+	}{
+		Levels: levels,
+	})
+}
+
+func newJSONError(err error, redact bool) jsonError {
+	jsonErr := jsonError{Message: errorMessage(err, redact)}
+	if f, ok := err.(fielder); ok {
+		jsonErr.Fields = redactFields(f.Fields(), redact)
+	}
+	return jsonErr
+}
@@ -0,0 +1,51 @@
+package errorwrap
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrorWrapperMarshalJSON(t *testing.T) {
+	RedactFields("token")
+	t.Cleanup(func() {
+		redactionMu.Lock()
+		delete(redactionFields, "token")
+		redactionMu.Unlock()
+	})
+
+	root := WrapWithMessage(errors.New("standard error"), "query users", ErrorInfraDatabase, ErrorSensitiveCredential)
+	err := AppendFields(Wrap(root, ErrorDomain), Fields{"token": "abc", "id": 1})
+
+	type level struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		Context string                 `json:"context"`
+		Fields  map[string]interface{} `json:"fields"`
+		Stack   []string               `json:"stack"`
+	}
+	var got struct {
+		Levels []level `json:"levels"`
+	}
+
+	data, e := json.Marshal(err)
+	assert.NoError(t, e)
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Len(t, got.Levels, 3)
+	assert.Equal(t, "error domain layer", got.Levels[0].Errors[0].Message)
+	assert.Equal(t, map[string]interface{}{"token": "[REDACTED]", "id": float64(1)}, got.Levels[0].Fields)
+	assert.NotEmpty(t, got.Levels[0].Stack)
+	assert.Equal(t, "error infra layer", got.Levels[1].Errors[0].Message)
+	assert.Equal(t, "[REDACTED]", got.Levels[1].Errors[1].Message)
+	assert.Equal(t, "query users", got.Levels[1].Context)
+	assert.Equal(t, "standard error", got.Levels[2].Errors[0].Message)
+	assert.NotContains(t, string(data), "admin")
+	assert.NotContains(t, string(data), `"details"`)
+
+	data, e = json.Marshal(Unsafe(err))
+	assert.NoError(t, e)
+	assert.Contains(t, string(data), "invalid password for user admin")
+	assert.Contains(t, string(data), `"token":"abc"`)
+}
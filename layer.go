@@ -0,0 +1,167 @@
+package errorwrap
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+type layerer interface {
+	Layer() string
+}
+
+var (
+	layerMu       sync.RWMutex
+	layerPackages = map[string]string{}
+)
+
+// WithLayer sets the layer of an ErrorDefinition, e.g. "infra" for ErrorInfraDatabase. A level containing the
+// definition belongs to the layer, unless the level has its own layer set using SetLayer.
+func WithLayer(layer string) DefinitionOption {
+	return func(d *errorDefinition) {
+		d.layer = layer
+	}
+}
+
+// SetLayer sets the layer of the current level of errWrapper, e.g. "usecase". It will return the same errWrapper
+// instance or new instance if errWrapper is not an ErrorWrapper. If errWrapper is nil then SetLayer returns nil.
+func SetLayer(errWrapper error, layer string) error {
+	if errWrapper == nil {
+		return nil
+	}
+	ew, ok := errWrapper.(*errorWrapper)
+	if !ok || ew == nil {
+		ew = newRootWrapper(callStack(), errWrapper)
+		if ew == nil {
+			return nil
+		}
+	}
+	ew.layer = layer
+	return ew
+}
+
+// RegisterLayer maps the packages whose import path is pkgPath, or starts with pkgPath followed by a slash, onto layer.
+// It is used to derive the layer of a level from the package where the level was created or wrapped. The longest
+// registered path wins.
+//
+//	errorwrap.RegisterLayer("github.com/acme/shop/internal/infra", "infra")
+func RegisterLayer(pkgPath string, layer string) {
+	layerMu.Lock()
+	defer layerMu.Unlock()
+	layerPackages[pkgPath] = layer
+}
+
+// Layer returns the layer of the current level, see LayerOf.
+func (e *errorWrapper) Layer() string {
+	return e.resolveLayer(true)
+}
+
+// resolveLayer returns the layer of the current level. If derived is false, the last element of the package path is
+// not used, so only the layers set using SetLayer, WithLayer or RegisterLayer are returned.
+func (e *errorWrapper) resolveLayer(derived bool) string {
+	if layer := e.explicitLayer(); layer != "" {
+		return layer
+	}
+	if e.stack == nil {
+		return ""
+	}
+	for _, pc := range *e.stack {
+		fn := runtime.FuncForPC(Frame(pc).pc())
+		if fn == nil {
+			return ""
+		}
+		pkgPath := packagePath(fn.Name())
+		if file, _ := fn.FileLine(Frame(pc).pc()); isModulePackage(pkgPath) && !strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		layer, registered := packageLayer(pkgPath)
+		if !registered && !derived {
+			return ""
+		}
+		return layer
+	}
+	return ""
+}
+
+// explicitLayer returns the layer set using SetLayer or WithLayer, or an empty string if there is none.
+func (e *errorWrapper) explicitLayer() string {
+	if e.layer != "" {
+		return e.layer
+	}
+	for _, err := range e.errors {
+		if def := definitionOf(err); def != nil && def.layer != "" {
+			return def.layer
+		}
+	}
+	return ""
+}
+
+// LayerOf returns the layer of the current level of err, e.g. "infra" or "usecase". It is the layer set using
+// SetLayer, or the layer of the first ErrorDefinition of CurrentError that has one. Otherwise it is derived from the
+// package of the function where the level was created or wrapped: the layer registered using RegisterLayer, or the
+// last element of the package path. The functions of this module, e.g. Retry or validation.New, are skipped, so a
+// level created by them belongs to the layer of their caller. If err is an ErrorDefinition, it returns the layer of
+// the definition. Otherwise it returns an empty string.
+func LayerOf(err error) string {
+	if l, ok := err.(layerer); ok {
+		return l.Layer()
+	}
+	if def := definitionOf(err); def != nil {
+		return def.layer
+	}
+	return ""
+}
+
+// OriginLayer returns the layer where err originated, i.e. the layer of the root level of ErrorWrapper stack.
+func OriginLayer(err error) string {
+	if ew, ok := err.(ErrorWrapper); ok && ew.RootCause() != nil {
+		return LayerOf(ew.RootCause())
+	}
+	return LayerOf(err)
+}
+
+// Layers returns the layer of every level of err, from current level to the root of ErrorWrapper stack.
+func Layers(err error) []string {
+	var layers []string
+	for ; err != nil; err = Unwrap(err) {
+		layers = append(layers, LayerOf(err))
+	}
+	return layers
+}
+
+// packageLayer returns the layer of pkgPath and whether it is registered using RegisterLayer. The layer of a package
+// that is not registered is the last element of its path.
+func packageLayer(pkgPath string) (string, bool) {
+	layerMu.RLock()
+	defer layerMu.RUnlock()
+	for path := pkgPath; path != ""; {
+		if layer, ok := layerPackages[path]; ok {
+			return layer, true
+		}
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return pkgPath[strings.LastIndex(pkgPath, "/")+1:], false
+}
+
+// libraryPath is the import path of this package, which is the path of its module as well.
+var libraryPath = reflect.TypeOf(errorWrapper{}).PkgPath()
+
+// isModulePackage checks whether pkgPath is a package of this module, e.g. errorwrap or errorwrap/validation.
+func isModulePackage(pkgPath string) bool {
+	return pkgPath == libraryPath || strings.HasPrefix(pkgPath, libraryPath+"/")
+}
+
+// packagePath returns the package path of a function name reported by runtime.Func.Name. The dots of the last element
+// of the package path are escaped in the function name, e.g. "gopkg.in/yaml%2ev3.Marshal".
+func packagePath(funcName string) string {
+	slash := strings.LastIndex(funcName, "/")
+	if dot := strings.Index(funcName[slash+1:], "."); dot >= 0 {
+		funcName = funcName[:slash+1+dot]
+	}
+	return strings.ReplaceAll(funcName, "%2e", ".")
+}
@@ -0,0 +1,128 @@
+package errorwrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorLayerInfra  = New("error infra", WithLayer("infra"))
+	ErrorLayerDomain = New("error domain", WithLayer("domain"))
+)
+
+func registerLayer(t *testing.T, pkgPath string, layer string) {
+	RegisterLayer(pkgPath, layer)
+	t.Cleanup(func() {
+		layerMu.Lock()
+		delete(layerPackages, pkgPath)
+		layerMu.Unlock()
+	})
+}
+
+func TestLayers(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name            string
+		args            args
+		want            []string
+		wantOriginLayer string
+	}{
+		{name: "nil error", args: args{err: nil}, want: nil, wantOriginLayer: ""},
+		{name: "foreign error", args: args{err: errors.New("foreign")}, want: []string{""}, wantOriginLayer: ""},
+		{name: "definition", args: args{err: ErrorLayerInfra}, want: []string{"infra"}, wantOriginLayer: "infra"},
+		{name: "derived from package", args: args{err: appLayer()}, want: []string{"errorwrap", "errorwrap", "errorwrap", "errorwrap"}, wantOriginLayer: "errorwrap"},
+		{
+			name:            "definition layer",
+			args:            args{err: Wrap(NewError(ErrorInfraDatabase, ErrorLayerInfra), ErrorLayerDomain)},
+			want:            []string{"domain", "infra"},
+			wantOriginLayer: "infra",
+		},
+		{
+			name:            "level layer wins",
+			args:            args{err: SetLayer(Wrap(NewError(ErrorLayerInfra), ErrorLayerDomain), "usecase")},
+			want:            []string{"usecase", "infra"},
+			wantOriginLayer: "infra",
+		},
+		{
+			name:            "foreign root cause",
+			args:            args{err: SetLayer(Wrap(ErrorTestB, ErrorLayerDomain), "")},
+			want:            []string{"domain", "errorwrap"},
+			wantOriginLayer: "errorwrap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Layers(tt.args.err))
+			assert.Equal(t, tt.wantOriginLayer, OriginLayer(tt.args.err))
+			if len(tt.want) > 0 {
+				assert.Equal(t, tt.want[0], LayerOf(tt.args.err))
+			}
+		})
+	}
+}
+
+func TestRegisterLayer(t *testing.T) {
+	err := appLayer()
+	registerLayer(t, "github.com/anantadwi13", "vendor")
+	assert.Equal(t, "vendor", LayerOf(err))
+
+	registerLayer(t, "github.com/anantadwi13/errorwrap", "core")
+	assert.Equal(t, "core", LayerOf(err))
+	assert.Contains(t, fmt.Sprintf("%+s", err), "\n    layer: core\n")
+
+	registerLayer(t, "github.com/anantadwi13/errorwrap/validation", "validation")
+	assert.Equal(t, "core", LayerOf(err))
+}
+
+func TestSetLayer(t *testing.T) {
+	assert.Nil(t, SetLayer(nil, "infra"))
+
+	err := SetLayer(ErrorTestB, "infra")
+	ew, ok := err.(ErrorWrapper)
+	if assert.True(t, ok) {
+		assert.Equal(t, []error{ErrorTestB}, ew.CurrentError())
+		assert.Equal(t, "infra", LayerOf(ew))
+	}
+
+	base := NewError(ErrorTestA)
+	assert.Same(t, base, SetLayer(base, "domain"))
+}
+
+func TestLayerFormat(t *testing.T) {
+	err := Wrap(SetLayer(NewErrorWithMessage("query", ErrorInfraDatabase), "infra"), ErrorDomain)
+	// the derived layer is encoded in JSON only
+	assert.Equal(t, " -  error domain layer\n -  error infra layer\n    context: query\n    layer: infra",
+		fmt.Sprintf("%+s", err))
+
+	b, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	var got struct {
+		Levels []struct {
+			Layer string `json:"layer"`
+		} `json:"levels"`
+	}
+	assert.NoError(t, json.Unmarshal(b, &got))
+	if assert.Len(t, got.Levels, 2) {
+		assert.Equal(t, "errorwrap", got.Levels[0].Layer)
+		assert.Equal(t, "infra", got.Levels[1].Layer)
+	}
+}
+
+func TestIsModulePackage(t *testing.T) {
+	assert.True(t, isModulePackage("github.com/anantadwi13/errorwrap"))
+	assert.True(t, isModulePackage("github.com/anantadwi13/errorwrap/validation"))
+	assert.False(t, isModulePackage("github.com/anantadwi13/errorwrap_test"))
+	assert.False(t, isModulePackage("github.com/anantadwi13/errorwrapper"))
+	assert.False(t, isModulePackage("main"))
+}
+
+func TestPackagePath(t *testing.T) {
+	assert.Equal(t, "runtime", packagePath("runtime.main"))
+	assert.Equal(t, "github.com/a/b", packagePath("github.com/a/b.(*T).m.func1"))
+	assert.Equal(t, "gopkg.in/yaml.v3", packagePath("gopkg.in/yaml%2ev3.Marshal"))
+}
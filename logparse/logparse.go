@@ -0,0 +1,174 @@
+// Package logparse parses the text printed by fmt.Printf("%+v", err) for an errorwrap.ErrorWrapper back into
+// structured data, so historical logs can be analyzed. Each level starts with " -  ", followed by the other errors of
+// the level and the "context: ", "layer: ", "time: " and "fields: " lines, all indented by four spaces. The stack
+// trace comes after an empty line, as a function line followed by a tab indented "file:line" line for each frame.
+package logparse
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	levelPrefix  = " -  "
+	indentPrefix = "    "
+	contextLabel = "context: "
+	layerLabel   = "layer: "
+	timeLabel    = "time: "
+	fieldsLabel  = "fields: "
+)
+
+// Error is a parsed error dump.
+type Error struct {
+	// Levels are ordered from the current level to the root.
+	Levels []Level `json:"levels"`
+	// Stack is the stack trace of the root cause, from the innermost frame.
+	Stack []Frame `json:"stack,omitempty"`
+}
+
+// Level is a parsed level of an error dump.
+type Level struct {
+	Errors  []string          `json:"errors"`
+	Context string            `json:"context,omitempty"`
+	Layer   string            `json:"layer,omitempty"`
+	Time    *time.Time        `json:"time,omitempty"`
+	Elapsed string            `json:"elapsed,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Frame is a parsed stack frame.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	// Line is 0 if the line number was not printed.
+	Line int `json:"line,omitempty"`
+}
+
+// Parse parses the first error dump of text. It returns nil if text contains no error dump.
+func Parse(text string) *Error {
+	errs, _ := ParseAll(strings.NewReader(text))
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+// ParseAll parses every error dump read from r. Lines that are not part of an error dump are skipped. The first line
+// of a dump may carry a prefix before " -  ", such as the timestamp written by the log package.
+func ParseAll(r io.Reader) ([]*Error, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var errs []*Error
+	for i := 0; i < len(lines); {
+		start := strings.Index(lines[i], levelPrefix)
+		if start < 0 {
+			i++
+			continue
+		}
+		lines[i] = lines[i][start:]
+		var e *Error
+		e, i = parseError(lines, i)
+		errs = append(errs, e)
+	}
+	return errs, nil
+}
+
+// parseError parses the error dump starting at lines[i] and returns the index of the first line after the dump.
+func parseError(lines []string, i int) (*Error, int) {
+	e := &Error{}
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, levelPrefix):
+			e.Levels = append(e.Levels, Level{Errors: []string{strings.TrimPrefix(line, levelPrefix)}})
+		case strings.HasPrefix(line, indentPrefix+contextLabel):
+			e.Levels[len(e.Levels)-1].Context = strings.TrimPrefix(line, indentPrefix+contextLabel)
+		case strings.HasPrefix(line, indentPrefix+layerLabel):
+			e.Levels[len(e.Levels)-1].Layer = strings.TrimPrefix(line, indentPrefix+layerLabel)
+		case strings.HasPrefix(line, indentPrefix+timeLabel):
+			level := &e.Levels[len(e.Levels)-1]
+			level.Time, level.Elapsed = parseTime(strings.TrimPrefix(line, indentPrefix+timeLabel))
+		case strings.HasPrefix(line, indentPrefix+fieldsLabel):
+			e.Levels[len(e.Levels)-1].Fields = parseFields(strings.TrimPrefix(line, indentPrefix+fieldsLabel))
+		case strings.HasPrefix(line, indentPrefix):
+			level := &e.Levels[len(e.Levels)-1]
+			level.Errors = append(level.Errors, strings.TrimPrefix(line, indentPrefix))
+		default:
+			if line == "" {
+				e.Stack, i = parseStack(lines, i+1)
+				if e.Stack == nil {
+					i--
+				}
+			}
+			return e, i
+		}
+	}
+	return e, i
+}
+
+// parseStack parses the frames starting at lines[i] and returns the index of the first line after the frames.
+func parseStack(lines []string, i int) ([]Frame, int) {
+	var frames []Frame
+	for ; i+1 < len(lines); i += 2 {
+		function, location := lines[i], lines[i+1]
+		if function == "" || strings.HasPrefix(function, "\t") || strings.HasPrefix(function, levelPrefix) ||
+			!strings.HasPrefix(location, "\t") {
+			break
+		}
+		frames = append(frames, parseFrame(function, strings.TrimPrefix(location, "\t")))
+	}
+	return frames, i
+}
+
+func parseFrame(function, location string) Frame {
+	frame := Frame{Function: function, File: location}
+	if i := strings.LastIndex(location, ":"); i > 0 {
+		if line, err := strconv.Atoi(location[i+1:]); err == nil {
+			frame.File, frame.Line = location[:i], line
+		}
+	}
+	return frame
+}
+
+// parseTime parses "2006-01-02T15:04:05.000000Z07:00 (+1.5ms)", the elapsed time being optional.
+func parseTime(text string) (*time.Time, string) {
+	elapsed := ""
+	if i := strings.Index(text, " (+"); i >= 0 && strings.HasSuffix(text, ")") {
+		text, elapsed = text[:i], text[i+3:len(text)-1]
+	}
+	t, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return nil, elapsed
+	}
+	return &t, elapsed
+}
+
+// parseFields parses "key=value key2=value2". A value containing spaces is kept whole as long as the words after the
+// spaces do not look like another key.
+func parseFields(text string) map[string]string {
+	fields := map[string]string{}
+	lastKey := ""
+	for _, word := range strings.Split(text, " ") {
+		if i := strings.Index(word, "="); i > 0 {
+			lastKey = word[:i]
+			fields[lastKey] = word[i+1:]
+			continue
+		}
+		if lastKey != "" {
+			fields[lastKey] += " " + word
+		}
+	}
+	return fields
+}
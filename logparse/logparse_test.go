@@ -0,0 +1,167 @@
+package logparse
+
+import (
+	"errors"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	ErrorInfraDatabase = errorwrap.New("error database")
+	ErrorDomainUser    = errorwrap.New("error domain user")
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	type args struct {
+		text string
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Error
+	}{
+		{
+			name: "no error dump",
+			args: args{text: "server started\nlistening on :8080\n"},
+			want: nil,
+		},
+		{
+			name: "levels without stack",
+			args: args{text: " -  error domain user\n" +
+				"    context: find user\n" +
+				" -  error database\n" +
+				"    connection refused\n" +
+				"    fields: id=42 query=select * from users\n"},
+			want: &Error{
+				Levels: []Level{
+					{Errors: []string{"error domain user"}, Context: "find user"},
+					{
+						Errors: []string{"error database", "connection refused"},
+						Fields: map[string]string{"id": "42", "query": "select * from users"},
+					},
+				},
+			},
+		},
+		{
+			name: "levels with stack",
+			args: args{text: " -  error database\n" +
+				"\n" +
+				"main.main\n" +
+				"\t/go/src/app/main.go:21\n" +
+				"runtime.main\n" +
+				"\t$GOROOT/src/runtime/proc.go\n"},
+			want: &Error{
+				Levels: []Level{{Errors: []string{"error database"}}},
+				Stack: []Frame{
+					{Function: "main.main", File: "/go/src/app/main.go", Line: 21},
+					{Function: "runtime.main", File: "$GOROOT/src/runtime/proc.go"},
+				},
+			},
+		},
+		{
+			name: "times",
+			args: args{text: " -  error domain user\n" +
+				"    time: 2022-01-02T15:04:05.000150+07:00 (+150µs)\n" +
+				" -  error database\n" +
+				"    time: 2022-01-02T15:04:05.000000+07:00\n" +
+				" -  error cache\n" +
+				"    time: invalid\n"},
+			want: &Error{
+				Levels: []Level{
+					{
+						Errors:  []string{"error domain user"},
+						Time:    timePtr(time.Date(2022, 1, 2, 15, 4, 5, 150000, time.FixedZone("", 7*60*60))),
+						Elapsed: "150µs",
+					},
+					{
+						Errors: []string{"error database"},
+						Time:   timePtr(time.Date(2022, 1, 2, 15, 4, 5, 0, time.FixedZone("", 7*60*60))),
+					},
+					{Errors: []string{"error cache"}},
+				},
+			},
+		},
+		{
+			name: "log prefix",
+			args: args{text: "2022/01/02 15:04:05 request failed\n" +
+				"2022/01/02 15:04:05  -  error database\n" +
+				"    context: ping\n" +
+				"2022/01/02 15:04:06 shutting down\n"},
+			want: &Error{
+				Levels: []Level{{Errors: []string{"error database"}, Context: "ping"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.args.text))
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	text := "starting\n" +
+		" -  error a\n" +
+		"\n" +
+		"main.a\n" +
+		"\t/app/a.go:1\n" +
+		"\n" +
+		"retrying\n" +
+		" -  error b\n" +
+		"\n" +
+		" -  error c\n" +
+		"    context: c\n"
+
+	got, err := ParseAll(strings.NewReader(text))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Error{
+		{
+			Levels: []Level{{Errors: []string{"error a"}}},
+			Stack:  []Frame{{Function: "main.a", File: "/app/a.go", Line: 1}},
+		},
+		{Levels: []Level{{Errors: []string{"error b"}}}},
+		{Levels: []Level{{Errors: []string{"error c"}, Context: "c"}}},
+	}, got)
+}
+
+func TestParse_Format(t *testing.T) {
+	err := errorwrap.NewErrorWithMessage("query users", ErrorInfraDatabase, errors.New("connection refused"))
+	err = errorwrap.AppendFields(err, errorwrap.Fields{"id": 42, "table": "users"})
+	err = errorwrap.SetLayer(err, "infra")
+	err = errorwrap.WrapWithMessage(err, "find user", ErrorDomainUser)
+
+	got := Parse(fmt.Sprintf("%+v\n", err))
+	if !assert.NotNil(t, got) || !assert.Len(t, got.Levels, 2) {
+		return
+	}
+	if assert.NotNil(t, got.Levels[0].Time) && assert.NotNil(t, got.Levels[1].Time) {
+		assert.Equal(t, errorwrap.TimeOf(err).Truncate(time.Microsecond), got.Levels[0].Time.Local())
+	}
+	assert.NotEmpty(t, got.Levels[0].Elapsed)
+	assert.Empty(t, got.Levels[1].Elapsed)
+	for i := range got.Levels {
+		got.Levels[i].Time, got.Levels[i].Elapsed = nil, ""
+	}
+	assert.Equal(t, []Level{
+		{Errors: []string{"error domain user"}, Context: "find user"},
+		{
+			Errors:  []string{"error database", "connection refused"},
+			Context: "query users",
+			Layer:   "infra",
+			Fields:  map[string]string{"id": "42", "table": "users"},
+		},
+	}, got.Levels)
+	if assert.NotEmpty(t, got.Stack) {
+		assert.Equal(t, "github.com/anantadwi13/errorwrap/logparse.TestParse_Format", got.Stack[0].Function)
+		assert.True(t, strings.HasSuffix(got.Stack[0].File, "logparse_test.go"))
+		assert.NotZero(t, got.Stack[0].Line)
+	}
+}
@@ -0,0 +1,139 @@
+// Package metrics counts errors by definition, category and layer, e.g. to build a dashboard of ErrorInfraDatabase
+// per minute without parsing logs.
+//
+// Every ErrorDefinition placed in an observed error increments the counter of its Labels. The counters are kept by a
+// Recorder, such as MemoryRecorder for tests or the Prometheus adapter of the promerror module:
+//
+//	metrics.SetRecorder(recorder)
+//	...
+//	metrics.Observe(err)
+//
+// Observe can also be called by a report.Pipeline using NewReporter.
+package metrics
+
+import (
+	"context"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/anantadwi13/errorwrap/report"
+	"sync"
+)
+
+// Labels identifies a counter.
+type Labels struct {
+	// Code is the code of the ErrorDefinition, or the message it was defined with if it has no code, see
+	// errorwrap.DefinitionMessage. The fields of an instantiated Template are never part of it.
+	Code string
+	// Category is the category of the ErrorDefinition.
+	Category string
+	// Layer is the layer of the level containing the ErrorDefinition, see errorwrap.LayerOf.
+	Layer string
+}
+
+// Recorder keeps the error counters.
+type Recorder interface {
+	Inc(labels Labels)
+}
+
+var (
+	mu       sync.RWMutex
+	recorder Recorder
+)
+
+// SetRecorder sets the Recorder used by Observe. Observe does nothing until a Recorder is set.
+func SetRecorder(r Recorder) {
+	mu.Lock()
+	defer mu.Unlock()
+	recorder = r
+}
+
+// Observe counts err using the Recorder set by SetRecorder.
+func Observe(err error) {
+	mu.RLock()
+	r := recorder
+	mu.RUnlock()
+	if r == nil {
+		return
+	}
+	ObserveWith(r, err)
+}
+
+// ObserveWith increments the counter of every ErrorDefinition placed in err, from current level to the root of
+// ErrorWrapper stack. Foreign errors are not counted.
+func ObserveWith(r Recorder, err error) {
+	for ; err != nil; err = errorwrap.Unwrap(err) {
+		ew, ok := err.(errorwrap.ErrorWrapper)
+		if !ok {
+			observe(r, err, "")
+			return
+		}
+		layer := errorwrap.LayerOf(ew)
+		for _, curErr := range ew.CurrentError() {
+			observe(r, curErr, layer)
+		}
+	}
+}
+
+func observe(r Recorder, err error, layer string) {
+	if _, ok := err.(errorwrap.ErrorDefinition); !ok {
+		return
+	}
+	code := errorwrap.Code(err)
+	if code == "" {
+		code = errorwrap.DefinitionMessage(err)
+	}
+	r.Inc(Labels{
+		Code:     code,
+		Category: string(errorwrap.CategoryOf(err)),
+		Layer:    layer,
+	})
+}
+
+// NewReporter returns a report.Reporter counting the reported errors using r.
+func NewReporter(r Recorder) report.Reporter {
+	return report.ReporterFunc(func(ctx context.Context, event *report.Event) error {
+		ObserveWith(r, event.Err)
+		return nil
+	})
+}
+
+// MemoryRecorder is a Recorder keeping the counters in memory. It is meant for tests.
+type MemoryRecorder struct {
+	mu     sync.Mutex
+	counts map[Labels]int
+}
+
+// NewMemoryRecorder creates a MemoryRecorder.
+func NewMemoryRecorder() *MemoryRecorder {
+	return &MemoryRecorder{counts: map[Labels]int{}}
+}
+
+func (m *MemoryRecorder) Inc(labels Labels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[labels]++
+}
+
+// Count returns the counter of labels.
+func (m *MemoryRecorder) Count(labels Labels) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[labels]
+}
+
+// Counts returns a copy of every counter.
+func (m *MemoryRecorder) Counts() map[Labels]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[Labels]int, len(m.counts))
+	for labels, count := range m.counts {
+		counts[labels] = count
+	}
+	return counts
+}
+
+// Reset removes every counter.
+func (m *MemoryRecorder) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts = map[Labels]int{}
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/anantadwi13/errorwrap/report"
+	"github.com/anantadwi13/errorwrap/validation"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorInfraDatabase = errorwrap.New("error infra layer", errorwrap.WithCode("DATABASE"),
+		errorwrap.WithCategory(errorwrap.CategoryUnavailable))
+	ErrorDomain   = errorwrap.New("error domain layer")
+	ErrorRequired = errorwrap.New("required", errorwrap.WithCode("REQUIRED"))
+	ErrorUseCase  = errorwrap.New("error usecase layer", errorwrap.WithLayer("usecase"))
+	ErrorSecret   = errorwrap.New("error secret", errorwrap.Sensitive())
+	ErrorToken    = errorwrap.New("error token", errorwrap.Sensitive())
+
+	ErrorUserNotFound = errorwrap.NewTemplate("user {id} not found")
+)
+
+func TestObserveWith(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[Labels]int
+	}{
+		{
+			name: "nil error",
+			args: args{err: nil},
+			want: map[Labels]int{},
+		},
+		{
+			name: "foreign error",
+			args: args{err: errors.New("foreign")},
+			want: map[Labels]int{},
+		},
+		{
+			name: "definition",
+			args: args{err: ErrorInfraDatabase},
+			want: map[Labels]int{{Code: "DATABASE", Category: "unavailable"}: 1},
+		},
+		{
+			name: "every level",
+			args: args{err: errorwrap.Wrap(errorwrap.Wrap(errors.New("connection refused"), ErrorInfraDatabase), ErrorDomain)},
+			want: map[Labels]int{
+				{Code: "DATABASE", Category: "unavailable", Layer: "metrics"}: 1,
+				{Code: "error domain layer", Layer: "metrics"}:                1,
+			},
+		},
+		{
+			name: "layer of the wrap site",
+			args: args{err: errorwrap.Wrap(validation.New(validation.NewViolation("name", "required", ErrorRequired)),
+				ErrorDomain, ErrorDomain)},
+			want: map[Labels]int{
+				{Code: "VALIDATION_FAILED", Category: "invalid_argument", Layer: "metrics"}: 1,
+				{Code: "error domain layer", Layer: "metrics"}:                              2,
+			},
+		},
+		{
+			name: "explicit layer",
+			args: args{err: errorwrap.Wrap(errorwrap.SetLayer(errorwrap.NewError(ErrorDomain), "domain"), ErrorUseCase)},
+			want: map[Labels]int{
+				{Code: "error domain layer", Layer: "domain"}:   1,
+				{Code: "error usecase layer", Layer: "usecase"}: 1,
+			},
+		},
+		{
+			name: "template without code",
+			args: args{err: errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound.With(errorwrap.Fields{"id": 42})),
+				ErrorUserNotFound.With(errorwrap.Fields{"id": 43}))},
+			want: map[Labels]int{{Code: "user {id} not found", Layer: "metrics"}: 2},
+		},
+		{
+			name: "sensitive definitions",
+			args: args{err: errorwrap.Wrap(errorwrap.NewError(ErrorSecret), ErrorToken)},
+			want: map[Labels]int{
+				{Code: "error secret", Layer: "metrics"}: 1,
+				{Code: "error token", Layer: "metrics"}:  1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemoryRecorder()
+			ObserveWith(r, tt.args.err)
+			assert.Equal(t, tt.want, r.Counts())
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	err := errorwrap.NewError(ErrorInfraDatabase)
+	Observe(err)
+
+	r := NewMemoryRecorder()
+	SetRecorder(r)
+	defer SetRecorder(nil)
+
+	Observe(err)
+	Observe(err)
+	assert.Equal(t, 2, r.Count(Labels{Code: "DATABASE", Category: "unavailable", Layer: "metrics"}))
+
+	r.Reset()
+	assert.Empty(t, r.Counts())
+}
+
+func TestNewReporter(t *testing.T) {
+	r := NewMemoryRecorder()
+	p := report.New()
+	p.Register(NewReporter(r))
+
+	assert.NoError(t, p.Report(context.Background(), errorwrap.NewError(ErrorInfraDatabase)))
+	assert.Equal(t, map[Labels]int{{Code: "DATABASE", Category: "unavailable", Layer: "metrics"}: 1}, r.Counts())
+}
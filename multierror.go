@@ -0,0 +1,24 @@
+package errorwrap
+
+// multiError is implemented by errors aggregating several errors, e.g. *multierror.Error of
+// github.com/hashicorp/go-multierror. Errors implementing Unwrap() []error are not multiError, since they also carry
+// their own message, e.g. fmt.Errorf with several %w verbs.
+type multiError interface {
+	WrappedErrors() []error
+}
+
+// flattenErrors appends err into errs. If err is a multiError, its errors are appended recursively instead, so a
+// multiError placed in a level adds its errors into the same level. Nil and empty errors are skipped.
+func flattenErrors(errs []error, err error) []error {
+	switch e := err.(type) {
+	case nil:
+		return errs
+	case multiError:
+		for _, wrapped := range e.WrappedErrors() {
+			errs = flattenErrors(errs, wrapped)
+		}
+		return errs
+	default:
+		return append(errs, err)
+	}
+}
@@ -0,0 +1,118 @@
+package errorwrap
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// testMultiError mimics *multierror.Error of github.com/hashicorp/go-multierror.
+type testMultiError struct {
+	errs []error
+}
+
+func (m *testMultiError) Error() string {
+	msgs := make([]string, 0, len(m.errs))
+	for _, err := range m.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (m *testMultiError) WrappedErrors() []error {
+	return m.errs
+}
+
+// testWrapErrors mimics the errors returned by fmt.Errorf with several %w verbs.
+type testWrapErrors struct {
+	msg  string
+	errs []error
+}
+
+func (w *testWrapErrors) Error() string {
+	return w.msg
+}
+
+func (w *testWrapErrors) Unwrap() []error {
+	return w.errs
+}
+
+func TestFlattenErrors(t *testing.T) {
+	standardErr := errors.New("standard error")
+	wrapErrors := &testWrapErrors{msg: "load config: error a (fallback: error b)", errs: []error{ErrorTestA, ErrorTestB}}
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want []error
+	}{
+		{
+			name: "multi error",
+			args: args{err: NewError(&testMultiError{errs: []error{ErrorTestA, ErrorTestB}})},
+			want: []error{ErrorTestA, ErrorTestB},
+		},
+		{
+			name: "skip nil errors",
+			args: args{err: NewError(ErrorDomain, &testMultiError{errs: []error{ErrorTestA, nil, standardErr}})},
+			want: []error{ErrorDomain, ErrorTestA, standardErr},
+		},
+		{
+			name: "nested",
+			args: args{err: NewError(&testMultiError{errs: []error{ErrorTestA, &testMultiError{errs: []error{ErrorTestB}}}})},
+			want: []error{ErrorTestA, ErrorTestB},
+		},
+		{
+			name: "several wrapped errors kept as is",
+			args: args{err: NewError(wrapErrors)},
+			want: []error{wrapErrors},
+		},
+		{
+			name: "wrap parent",
+			args: args{err: Unwrap(Wrap(&testMultiError{errs: []error{ErrorTestA, ErrorTestB}}, ErrorDomain))},
+			want: []error{ErrorTestA, ErrorTestB},
+		},
+		{
+			name: "wrap errors",
+			args: args{err: Wrap(NewError(ErrorTestA), &testMultiError{errs: []error{ErrorDomain, ErrorTestB}})},
+			want: []error{ErrorDomain, ErrorTestB},
+		},
+		{
+			name: "append into",
+			args: args{err: AppendInto(NewError(ErrorDomain), &testMultiError{errs: []error{ErrorTestA}})},
+			want: []error{ErrorDomain, ErrorTestA},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ew, ok := tt.args.err.(ErrorWrapper)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.want, ew.CurrentError())
+			}
+		})
+	}
+}
+
+func TestFlattenErrorsEmpty(t *testing.T) {
+	empty := &testMultiError{}
+	tests := []struct {
+		name   string
+		create func() error
+	}{
+		{name: "NewError", create: func() error { return NewError(empty) }},
+		{name: "Wrap", create: func() error { return Wrap(nil, &testMultiError{errs: []error{nil}}) }},
+		{name: "AppendFields", create: func() error { return AppendFields(empty, Fields{"id": 1}) }},
+		{name: "AppendDetails", create: func() error { return AppendDetails(empty, "detail") }},
+		{name: "SetLayer", create: func() error { return SetLayer(empty, "infra") }},
+		{name: "MarkRetryable", create: func() error { return MarkRetryable(empty, 0) }},
+		{name: "MarkPermanent", create: func() error { return MarkPermanent(empty) }},
+		{name: "Classify", create: func() error { return Classify(empty) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Nil(t, tt.create())
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package errorwrap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NormalizeOptions configures the output of Normalized.
+type NormalizeOptions struct {
+	// HideRuntimeFrames hides the frames of the Go runtime, the testing package and the main function generated by go
+	// test.
+	HideRuntimeFrames bool
+	// HideLineNumbers omits the line numbers of the frames.
+	HideLineNumbers bool
+	// HideTimes omits the creation time of the levels.
+	HideTimes bool
+}
+
+// GoldenOptions are the NormalizeOptions used for snapshot tests. They remove everything that depends on the machine,
+// the Go version, the position of the code in the file, or the time.
+var GoldenOptions = NormalizeOptions{
+	HideRuntimeFrames: true,
+	HideLineNumbers:   true,
+	HideTimes:         true,
+}
+
+// Normalized returns a formatter that prints err with a machine independent stack trace, so the output can be
+// compared with a snapshot. The path of a source file is printed as "<module path>/<path relative to the module
+// root>", files of the Go root as "$GOROOT/<path>", and files of the module cache as "<module>@<version>/<path>".
+// Normalized output is redacted in the same way as the default output.
+//
+//	fmt.Printf("%+v\n", errorwrap.Normalized(err, errorwrap.GoldenOptions))
+func Normalized(err error, opts NormalizeOptions) fmt.Formatter {
+	return &normalizedFormatter{err: err, opts: opts}
+}
+
+type normalizedFormatter struct {
+	err  error
+	opts NormalizeOptions
+}
+
+func (n *normalizedFormatter) Format(s fmt.State, verb rune) {
+	switch e := n.err.(type) {
+	case nil:
+		io.WriteString(s, "<nil>")
+	case *errorWrapper:
+		e.format(s, verb, true, &n.opts)
+	case fmt.Formatter:
+		e.Format(s, verb)
+	default:
+		formatMessage(s, verb, e.Error())
+	}
+}
+
+func (o *NormalizeOptions) formatStack(s fmt.State, st *stack) {
+	if st == nil {
+		return
+	}
+	i := 0
+	for _, pc := range *st {
+		f := Frame(pc)
+		name := f.functionName()
+		if o.HideRuntimeFrames && (isRuntimeFunction(name) || isTestMain(f.fileName())) {
+			continue
+		}
+		if i > 0 {
+			io.WriteString(s, "\n")
+		}
+		i++
+		io.WriteString(s, name)
+		io.WriteString(s, "\n\t")
+		io.WriteString(s, normalizePath(f.fileName()))
+		if !o.HideLineNumbers {
+			io.WriteString(s, ":")
+			io.WriteString(s, strconv.Itoa(f.lineNumber()))
+		}
+	}
+}
+
+func isRuntimeFunction(name string) bool {
+	return strings.HasPrefix(name, "runtime.") || strings.HasPrefix(name, "testing.")
+}
+
+// isTestMain checks whether file is the main file generated by go test, whose path depends on the build.
+func isTestMain(file string) bool {
+	return strings.HasSuffix(file, "_testmain.go")
+}
+
+type moduleRoot struct {
+	dir  string
+	path string
+}
+
+var (
+	moduleRootMu    sync.Mutex
+	moduleRootCache = map[string]moduleRoot{}
+)
+
+func normalizePath(file string) string {
+	file = filepath.ToSlash(file)
+	if goroot := filepath.ToSlash(runtime.GOROOT()); goroot != "" && strings.HasPrefix(file, goroot+"/") {
+		return "$GOROOT" + strings.TrimPrefix(file, goroot)
+	}
+	if i := strings.LastIndex(file, "/pkg/mod/"); i >= 0 {
+		return file[i+len("/pkg/mod/"):]
+	}
+	if root := findModuleRoot(filepath.Dir(file)); root.path != "" {
+		return root.path + strings.TrimPrefix(file, root.dir)
+	}
+	return filepath.Base(file)
+}
+
+// findModuleRoot returns the nearest directory of dir containing a go.mod file, together with its module path.
+func findModuleRoot(dir string) moduleRoot {
+	moduleRootMu.Lock()
+	defer moduleRootMu.Unlock()
+
+	var visited []string
+	var root moduleRoot
+	for {
+		if cached, ok := moduleRootCache[dir]; ok {
+			root = cached
+			break
+		}
+		visited = append(visited, dir)
+		if path := modulePath(filepath.Join(dir, "go.mod")); path != "" {
+			root = moduleRoot{dir: filepath.ToSlash(dir), path: path}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	for _, d := range visited {
+		moduleRootCache[d] = root
+	}
+	return root
+}
+
+func modulePath(goMod string) string {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`)
+		}
+	}
+	return ""
+}
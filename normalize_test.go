@@ -0,0 +1,57 @@
+package errorwrap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNormalized(t *testing.T) {
+	err := Wrap(NewErrorWithMessage("find user", ErrorInfraDatabase), ErrorDomain)
+
+	golden := fmt.Sprintf("%+v", Normalized(err, GoldenOptions))
+	assert.True(t, strings.HasPrefix(golden, " -  error domain layer\n -  error infra layer\n    context: find user\n\n"))
+	assert.Contains(t, golden, "github.com/anantadwi13/errorwrap.TestNormalized\n\tgithub.com/anantadwi13/errorwrap/normalize_test.go")
+	assert.NotContains(t, golden, "runtime.")
+	assert.NotContains(t, golden, "testing.")
+	assert.NotContains(t, golden, ".go:")
+
+	withLines := fmt.Sprintf("%+v", Normalized(err, NormalizeOptions{}))
+	assert.Contains(t, withLines, "github.com/anantadwi13/errorwrap/normalize_test.go:")
+	assert.Contains(t, withLines, "$GOROOT/src/testing/testing.go:")
+
+	assert.Equal(t, fmt.Sprintf("%v", err), fmt.Sprintf("%v", Normalized(err, GoldenOptions)))
+	assert.Equal(t, fmt.Sprintf("%+s", err), fmt.Sprintf("%+s", Normalized(err, GoldenOptions)))
+	assert.Equal(t, "error test b", fmt.Sprintf("%+v", Normalized(ErrorTestB, GoldenOptions)))
+	assert.Equal(t, "<nil>", fmt.Sprintf("%+v", Normalized(nil, GoldenOptions)))
+	assert.Equal(t, "error test a", fmt.Sprintf("%+v", Normalized(ErrorTestA, GoldenOptions)))
+	assert.Equal(t, `"standard"`, fmt.Sprintf("%q", Normalized(errors.New("standard"), GoldenOptions)))
+}
+
+func TestNormalizePath(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+
+	type args struct {
+		file string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{name: "module file", args: args{file: file}, want: "github.com/anantadwi13/errorwrap/normalize_test.go"},
+		{name: "module subdirectory", args: args{file: filepath.Join(filepath.Dir(file), "validation", "validation.go")}, want: "github.com/anantadwi13/errorwrap/validation/validation.go"},
+		{name: "goroot file", args: args{file: filepath.Join(runtime.GOROOT(), "src", "runtime", "proc.go")}, want: "$GOROOT/src/runtime/proc.go"},
+		{name: "module cache file", args: args{file: "/home/user/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go"}, want: "github.com/pkg/errors@v0.9.1/errors.go"},
+		{name: "outside of module", args: args{file: "/nonexistent/dir/_testmain.go"}, want: "_testmain.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizePath(tt.args.file))
+		})
+	}
+}
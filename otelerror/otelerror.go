@@ -0,0 +1,156 @@
+// Package otelerror records errorwrap errors on OpenTelemetry spans.
+//
+// RecordError adds one "exception" event per level of an ErrorWrapper, following the OpenTelemetry semantic
+// conventions for exceptions, sets the span status from the category of the error, and appends the trace and span ids
+// into the fields of the error, so they are printed and encoded along with it:
+//
+//	ctx, span := tracer.Start(ctx, "FindUser")
+//	defer span.End()
+//	...
+//	if err != nil {
+//		return otelerror.RecordError(span, err)
+//	}
+package otelerror
+
+import (
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"sync"
+)
+
+// Attribute keys of the exception events.
+const (
+	ExceptionEventName      = "exception"
+	ExceptionTypeKey        = attribute.Key("exception.type")
+	ExceptionMessageKey     = attribute.Key("exception.message")
+	ExceptionStacktraceKey  = attribute.Key("exception.stacktrace")
+	ErrorwrapLevelKey       = attribute.Key("errorwrap.level")
+	ErrorwrapContextKey     = attribute.Key("errorwrap.context")
+	ErrorwrapCategoryKey    = attribute.Key("errorwrap.category")
+	ErrorwrapFingerprintKey = attribute.Key("errorwrap.fingerprint")
+)
+
+// Field keys appended into the error by RecordError.
+const (
+	FieldTraceID = "trace_id"
+	FieldSpanID  = "span_id"
+)
+
+var (
+	mu sync.RWMutex
+	// categoryStatus maps the categories onto span status codes. The categories caused by the caller do not mark the
+	// span as failed.
+	categoryStatus = map[errorwrap.Category]codes.Code{
+		errorwrap.CategoryInvalidArgument:  codes.Unset,
+		errorwrap.CategoryNotFound:         codes.Unset,
+		errorwrap.CategoryAlreadyExists:    codes.Unset,
+		errorwrap.CategoryPermissionDenied: codes.Unset,
+		errorwrap.CategoryUnauthenticated:  codes.Unset,
+		errorwrap.CategoryCanceled:         codes.Unset,
+	}
+)
+
+// RegisterCategoryStatus maps a Category onto a span status code. The categories that are not registered are mapped
+// onto codes.Error.
+func RegisterCategoryStatus(category errorwrap.Category, code codes.Code) {
+	mu.Lock()
+	defer mu.Unlock()
+	categoryStatus[category] = code
+}
+
+// StatusCode returns the span status code of err.
+func StatusCode(err error) codes.Code {
+	if err == nil {
+		return codes.Unset
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if code, ok := categoryStatus[errorwrap.CategoryOf(err)]; ok {
+		return code
+	}
+	return codes.Error
+}
+
+// RecordError records err on span. Every level of err becomes an exception event, in the order the levels were
+// created, i.e. from the root cause to the current level. The span status is set using StatusCode, with the
+// messages of the current level as description. If the span context is valid, RecordError returns err with the trace
+// and span ids appended into its current level using errorwrap.AppendFields, otherwise it returns err as is.
+func RecordError(span trace.Span, err error) error {
+	if err == nil || span == nil {
+		return err
+	}
+
+	fingerprint := errorwrap.Fingerprint(err)
+	var events [][]attribute.KeyValue
+	i := 0
+	for cur := err; cur != nil; cur, i = errorwrap.Unwrap(cur), i+1 {
+		attrs := []attribute.KeyValue{ErrorwrapLevelKey.Int(i), ErrorwrapFingerprintKey.String(fingerprint)}
+		ew, ok := cur.(errorwrap.ErrorWrapper)
+		if !ok {
+			attrs = append(attrs, ExceptionTypeKey.String(typeOf(cur)), ExceptionMessageKey.String(cur.Error()))
+			events = append(events, attrs)
+			break
+		}
+
+		if curErrs := ew.CurrentError(); len(curErrs) > 0 {
+			attrs = append(attrs, ExceptionTypeKey.String(typeOf(curErrs[0])))
+		}
+		attrs = append(attrs, ExceptionMessageKey.String(levelMessage(ew)))
+		if ctxMsg := ew.ContextMessage(); ctxMsg != "" {
+			attrs = append(attrs, ErrorwrapContextKey.String(errorwrap.Redact(ctxMsg)))
+		}
+		if st := ew.StackTrace(); len(st) > 0 {
+			attrs = append(attrs, ExceptionStacktraceKey.String(fmt.Sprintf("%+v", st)))
+		}
+		if category := errorwrap.CategoryOf(ew); category != errorwrap.CategoryUnknown {
+			attrs = append(attrs, ErrorwrapCategoryKey.String(string(category)))
+		}
+		events = append(events, attrs)
+	}
+
+	for j := len(events) - 1; j >= 0; j-- {
+		span.AddEvent(ExceptionEventName, trace.WithAttributes(events[j]...))
+	}
+
+	if code := StatusCode(err); code != codes.Unset {
+		description := err.Error()
+		if ew, ok := err.(errorwrap.ErrorWrapper); ok {
+			description = levelMessage(ew)
+		}
+		span.SetStatus(code, description)
+	}
+
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return err
+	}
+	return errorwrap.AppendFields(err, errorwrap.Fields{
+		FieldTraceID: sc.TraceID().String(),
+		FieldSpanID:  sc.SpanID().String(),
+	})
+}
+
+// levelMessage returns the messages of the current level of ew, without the messages of the levels below.
+func levelMessage(ew errorwrap.ErrorWrapper) string {
+	var messages []string
+	for _, curErr := range ew.CurrentError() {
+		messages = append(messages, curErr.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// typeOf returns the exception.type attribute of err. Definitions are identified by their code, falling back to their
+// message, since their Go type is shared by all of them.
+func typeOf(err error) string {
+	if code := errorwrap.Code(err); code != "" {
+		return code
+	}
+	if _, ok := err.(errorwrap.ErrorDefinition); ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%T", err)
+}
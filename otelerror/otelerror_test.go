@@ -0,0 +1,135 @@
+package otelerror
+
+import (
+	"context"
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"testing"
+)
+
+var (
+	ErrorInfraDatabase = errorwrap.New("error database", errorwrap.WithCode("DATABASE"),
+		errorwrap.WithCategory(errorwrap.CategoryUnavailable))
+	ErrorDomainUser = errorwrap.New("error domain user")
+	ErrorNotFound   = errorwrap.New("error not found", errorwrap.WithCategory(errorwrap.CategoryNotFound))
+)
+
+func infraLayer() error {
+	return errorwrap.Wrap(errors.New("connection refused"), ErrorInfraDatabase)
+}
+
+func domainLayer() error {
+	return errorwrap.WrapWithMessage(infraLayer(), "find user 42", ErrorDomainUser)
+}
+
+func newTracer() (*tracetest.InMemoryExporter, trace.Tracer) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return exporter, provider.Tracer("otelerror")
+}
+
+func attributes(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := map[attribute.Key]attribute.Value{}
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestRecordError(t *testing.T) {
+	exporter, tracer := newTracer()
+	_, span := tracer.Start(context.Background(), "FindUser")
+	err := RecordError(span, domainLayer())
+	span.End()
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	got := spans[0]
+	assert.Equal(t, codes.Error, got.Status.Code)
+	assert.Equal(t, "error domain user", got.Status.Description)
+
+	if assert.Len(t, got.Events, 3) {
+		root, infra, domain := attributes(got.Events[0].Attributes), attributes(got.Events[1].Attributes),
+			attributes(got.Events[2].Attributes)
+		for _, event := range got.Events {
+			assert.Equal(t, ExceptionEventName, event.Name)
+		}
+
+		assert.Equal(t, int64(2), root[ErrorwrapLevelKey].AsInt64())
+		assert.Equal(t, "*errors.errorString", root[ExceptionTypeKey].AsString())
+		assert.Equal(t, "connection refused", root[ExceptionMessageKey].AsString())
+
+		assert.Equal(t, int64(1), infra[ErrorwrapLevelKey].AsInt64())
+		assert.Equal(t, "DATABASE", infra[ExceptionTypeKey].AsString())
+		assert.Equal(t, "error database", infra[ExceptionMessageKey].AsString())
+		assert.Equal(t, "unavailable", infra[ErrorwrapCategoryKey].AsString())
+		assert.Contains(t, infra[ExceptionStacktraceKey].AsString(), "otelerror.infraLayer\n\t")
+
+		assert.Equal(t, int64(0), domain[ErrorwrapLevelKey].AsInt64())
+		assert.Equal(t, "error domain user", domain[ExceptionTypeKey].AsString())
+		assert.Equal(t, "find user 42", domain[ErrorwrapContextKey].AsString())
+		assert.True(t, strings.HasPrefix(domain[ExceptionStacktraceKey].AsString(),
+			"github.com/anantadwi13/errorwrap/otelerror.domainLayer\n\t"))
+		assert.Equal(t, errorwrap.Fingerprint(err), domain[ErrorwrapFingerprintKey].AsString())
+	}
+
+	assert.Equal(t, errorwrap.Fields{
+		FieldTraceID: got.SpanContext.TraceID().String(),
+		FieldSpanID:  got.SpanContext.SpanID().String(),
+	}, errorwrap.FieldsOf(err))
+}
+
+func TestRecordError_Status(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name            string
+		args            args
+		wantCode        codes.Code
+		wantDescription string
+	}{
+		{name: "without category", args: args{err: errorwrap.NewError(ErrorDomainUser)}, wantCode: codes.Error, wantDescription: "error domain user"},
+		{name: "caller category", args: args{err: errorwrap.Wrap(errorwrap.NewError(ErrorNotFound), ErrorDomainUser)}, wantCode: codes.Unset},
+		{name: "foreign error", args: args{err: errors.New("foreign")}, wantCode: codes.Error, wantDescription: "foreign"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, tracer := newTracer()
+			_, span := tracer.Start(context.Background(), "test")
+			RecordError(span, tt.args.err)
+			span.End()
+
+			got := exporter.GetSpans()[0]
+			assert.Equal(t, tt.wantCode, got.Status.Code)
+			assert.Equal(t, tt.wantDescription, got.Status.Description)
+			assert.NotEmpty(t, got.Events)
+		})
+	}
+}
+
+func TestRecordError_InvalidSpan(t *testing.T) {
+	err := errorwrap.NewError(ErrorDomainUser)
+	got := RecordError(trace.SpanFromContext(context.Background()), err)
+	assert.Equal(t, err, got)
+	assert.Nil(t, errorwrap.FieldsOf(got))
+	assert.Nil(t, RecordError(trace.SpanFromContext(context.Background()), nil))
+}
+
+func TestRegisterCategoryStatus(t *testing.T) {
+	defer RegisterCategoryStatus(errorwrap.CategoryNotFound, codes.Unset)
+	err := errorwrap.NewError(ErrorNotFound)
+	assert.Equal(t, codes.Unset, StatusCode(err))
+	RegisterCategoryStatus(errorwrap.CategoryNotFound, codes.Error)
+	assert.Equal(t, codes.Error, StatusCode(err))
+	assert.Equal(t, codes.Unset, StatusCode(nil))
+}
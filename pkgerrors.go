@@ -0,0 +1,110 @@
+package errorwrap
+
+import (
+	"errors"
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// stackTracer is implemented by the errors of github.com/pkg/errors that carry a stack trace.
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// Cause returns the error below the current level, or the first error of CurrentError for the root level, so
+// pkgerrors.Cause returns the error that originally caused err.
+func (e *errorWrapper) Cause() error {
+	if e.parentError != nil {
+		return e.parentError
+	}
+	if len(e.errors) > 0 {
+		return e.errors[0]
+	}
+	return nil
+}
+
+// importStack replaces the stack of e by the stack of an error of CurrentError created by github.com/pkg/errors. If an
+// error has several stacks, the deepest one is used since it is the closest to the origin. It returns false if there
+// is none or if the capture is disabled.
+func (e *errorWrapper) importStack() bool {
+	if !CaptureEnabled() {
+		return false
+	}
+	for _, err := range e.errors {
+		var found stackTracer
+		for ; err != nil; err = errors.Unwrap(err) {
+			if st, ok := err.(stackTracer); ok {
+				found = st
+			}
+		}
+		if found == nil {
+			continue
+		}
+		pkgStack := found.StackTrace()
+		st := make(stack, len(pkgStack))
+		for i, f := range pkgStack {
+			st[i] = uintptr(f)
+		}
+		e.stack = &st
+		return true
+	}
+	return false
+}
+
+// PkgErrors converts st into a github.com/pkg/errors stack trace.
+func (st StackTrace) PkgErrors() pkgerrors.StackTrace {
+	if st == nil {
+		return nil
+	}
+	pkgStack := make(pkgerrors.StackTrace, len(st))
+	for i, f := range st {
+		pkgStack[i] = pkgerrors.Frame(f)
+	}
+	return pkgStack
+}
+
+// PkgErrors returns err adapted to the libraries expecting the errors of github.com/pkg/errors, e.g. error trackers
+// looking for the StackTrace() pkgerrors.StackTrace method. The stack trace is the one of the root cause, like in the
+// output of "%+v". The adapter formats, unwraps and matches like err. If err is nil then PkgErrors returns nil.
+func PkgErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &pkgErrorsAdapter{err: err}
+}
+
+type pkgErrorsAdapter struct {
+	err error
+}
+
+func (a *pkgErrorsAdapter) Error() string {
+	return a.err.Error()
+}
+
+func (a *pkgErrorsAdapter) Cause() error {
+	return a.err
+}
+
+func (a *pkgErrorsAdapter) Unwrap() error {
+	return a.err
+}
+
+func (a *pkgErrorsAdapter) StackTrace() pkgerrors.StackTrace {
+	ew, ok := a.err.(*errorWrapper)
+	if !ok || ew == nil {
+		return nil
+	}
+	root := ew.root()
+	if root.stack == nil {
+		return nil
+	}
+	return root.StackTrace().PkgErrors()
+}
+
+func (a *pkgErrorsAdapter) Format(s fmt.State, verb rune) {
+	if f, ok := a.err.(fmt.Formatter); ok {
+		f.Format(s, verb)
+		return
+	}
+	formatMessage(s, verb, a.err.Error())
+}
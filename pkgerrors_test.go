@@ -0,0 +1,97 @@
+package errorwrap
+
+import (
+	"errors"
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func pkgErrorsOrigin() error {
+	return pkgerrors.New("error pkg/errors")
+}
+
+func TestCause(t *testing.T) {
+	origin := pkgErrorsOrigin()
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want error
+	}{
+		{name: "error definition", args: args{err: ErrorTestA}, want: ErrorTestA},
+		{name: "root level", args: args{err: NewError(ErrorTestB, ErrorTestA)}, want: ErrorTestB},
+		{name: "error wrapper", args: args{err: appLayer()}, want: ErrorInfraDatabase},
+		{name: "pkg/errors root cause", args: args{err: Wrap(pkgerrors.Wrap(origin, "query"), ErrorDomain)}, want: origin},
+		{name: "pkg/errors wrapping errorwrap", args: args{err: pkgerrors.Wrap(domainLayer(), "usecase")}, want: ErrorInfraDatabase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pkgerrors.Cause(tt.args.err))
+		})
+	}
+}
+
+func TestImportStack(t *testing.T) {
+	origin := pkgErrorsOrigin()
+	wrapped := pkgerrors.Wrap(fmt.Errorf("query: %w", pkgerrors.WithStack(origin)), "repository")
+	originStack := origin.(stackTracer).StackTrace()
+
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{name: "NewError", args: args{err: NewError(ErrorInfraDatabase, origin)}},
+		{name: "NewErrorWithMessage", args: args{err: NewErrorWithMessage("query", wrapped)}},
+		{name: "AppendInto", args: args{err: AppendInto(nil, wrapped)}},
+		{name: "Wrap", args: args{err: Wrap(wrapped, ErrorDomain).(ErrorWrapper).ParentError()}},
+		{name: "WrapWithMessage", args: args{err: WrapWithMessage(origin, "query").(ErrorWrapper)}},
+		{name: "AppendFields", args: args{err: AppendFields(wrapped, Fields{"id": 1})}},
+		{name: "AppendDetails", args: args{err: AppendDetails(wrapped, "detail")}},
+		{name: "SetLayer", args: args{err: SetLayer(wrapped, "infra")}},
+		{name: "MarkRetryable", args: args{err: MarkRetryable(wrapped, 0)}},
+		{name: "Classify", args: args{err: Classify(wrapped)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, originStack, tt.args.err.(ErrorWrapper).StackTrace().PkgErrors())
+		})
+	}
+
+	err := Wrap(origin, ErrorDomain)
+	assert.NotEqual(t, originStack, err.(ErrorWrapper).StackTrace().PkgErrors())
+	assert.Contains(t, fmt.Sprintf("%+v", err), "errorwrap.pkgErrorsOrigin\n\t")
+
+	SetCaptureEnabled(false)
+	defer SetCaptureEnabled(true)
+	assert.Empty(t, NewError(origin).(ErrorWrapper).StackTrace())
+}
+
+func TestPkgErrors(t *testing.T) {
+	assert.Nil(t, PkgErrors(nil))
+
+	err := appLayer()
+	adapted := PkgErrors(err)
+	st, ok := adapted.(stackTracer)
+	if assert.True(t, ok) {
+		assert.Equal(t, err.(ErrorWrapper).RootCause().StackTrace().PkgErrors(), st.StackTrace())
+		assert.Contains(t, fmt.Sprintf("%+v", st.StackTrace()), "errorwrap.infraDbLayer\n\t")
+	}
+	assert.Equal(t, err.Error(), adapted.Error())
+	assert.Equal(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", adapted))
+	assert.True(t, Is(adapted, ErrorInfraDatabase))
+	assert.True(t, errors.Is(adapted, ErrorApp))
+	assert.Equal(t, ErrorInfraDatabase, pkgerrors.Cause(adapted))
+
+	foreign := PkgErrors(ErrorTestB)
+	assert.Nil(t, foreign.(stackTracer).StackTrace())
+	assert.Equal(t, "error test b", fmt.Sprintf("%v", foreign))
+
+	assert.Nil(t, StackTrace(nil).PkgErrors())
+}
@@ -0,0 +1,79 @@
+// Package promerror exposes the error counters of the errorwrap metrics package as a Prometheus counter:
+//
+//	recorder, err := promerror.NewRecorder(prometheus.DefaultRegisterer)
+//	...
+//	metrics.SetRecorder(recorder)
+//
+// The counter is errorwrap_errors_total, labeled by code, category and layer.
+package promerror
+
+import (
+	"github.com/anantadwi13/errorwrap"
+	"github.com/anantadwi13/errorwrap/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ErrorRegister = errorwrap.New("unable to register prometheus collector")
+)
+
+// Option configures a Recorder created by NewRecorder.
+type Option func(opts *prometheus.CounterOpts)
+
+// WithNamespace sets the namespace of the counter, e.g. "myapp" for myapp_errorwrap_errors_total.
+func WithNamespace(namespace string) Option {
+	return func(opts *prometheus.CounterOpts) {
+		opts.Namespace = namespace
+	}
+}
+
+// WithConstLabels sets labels having the same value for every error, e.g. the service name.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(opts *prometheus.CounterOpts) {
+		opts.ConstLabels = labels
+	}
+}
+
+// Recorder is a metrics.Recorder incrementing a Prometheus counter.
+type Recorder struct {
+	counter *prometheus.CounterVec
+}
+
+var _ metrics.Recorder = (*Recorder)(nil)
+
+// NewRecorder creates a Recorder and registers its counter into registerer. If registerer is nil, the counter is not
+// registered, it can be registered later since Recorder is a prometheus.Collector.
+func NewRecorder(registerer prometheus.Registerer, opts ...Option) (*Recorder, error) {
+	counterOpts := prometheus.CounterOpts{
+		Subsystem: "errorwrap",
+		Name:      "errors_total",
+		Help:      "Number of errors by errorwrap definition code, category and layer.",
+	}
+	for _, opt := range opts {
+		opt(&counterOpts)
+	}
+
+	r := &Recorder{
+		counter: prometheus.NewCounterVec(counterOpts, []string{"code", "category", "layer"}),
+	}
+	if registerer != nil {
+		if err := registerer.Register(r.counter); err != nil {
+			return nil, errorwrap.Wrap(err, ErrorRegister)
+		}
+	}
+	return r, nil
+}
+
+func (r *Recorder) Inc(labels metrics.Labels) {
+	r.counter.WithLabelValues(labels.Code, labels.Category, labels.Layer).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (r *Recorder) Describe(ch chan<- *prometheus.Desc) {
+	r.counter.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (r *Recorder) Collect(ch chan<- prometheus.Metric) {
+	r.counter.Collect(ch)
+}
@@ -0,0 +1,54 @@
+package promerror
+
+import (
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/anantadwi13/errorwrap/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var (
+	ErrorInfraDatabase = errorwrap.New("error infra layer", errorwrap.WithCode("DATABASE"),
+		errorwrap.WithCategory(errorwrap.CategoryUnavailable))
+	ErrorDomain = errorwrap.New("error domain layer")
+)
+
+func TestRecorder(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r, err := NewRecorder(registry, WithNamespace("test"), WithConstLabels(prometheus.Labels{"service": "users"}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i := 0; i < 2; i++ {
+		metrics.ObserveWith(r, errorwrap.Wrap(errorwrap.Wrap(errors.New("refused"), ErrorInfraDatabase), ErrorDomain))
+	}
+
+	expected := `
+# HELP test_errorwrap_errors_total Number of errors by errorwrap definition code, category and layer.
+# TYPE test_errorwrap_errors_total counter
+test_errorwrap_errors_total{category="",code="error domain layer",layer="promerror",service="users"} 2
+test_errorwrap_errors_total{category="unavailable",code="DATABASE",layer="promerror",service="users"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+
+	_, err = NewRecorder(registry, WithNamespace("test"), WithConstLabels(prometheus.Labels{"service": "users"}))
+	assert.True(t, errorwrap.Is(err, ErrorRegister))
+}
+
+func TestRecorder_Collector(t *testing.T) {
+	r, err := NewRecorder(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	r.Inc(metrics.Labels{Code: "DATABASE", Category: "unavailable", Layer: "infra"})
+
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(r))
+	assert.Equal(t, 1, testutil.CollectAndCount(r))
+	assert.Equal(t, float64(1), testutil.ToFloat64(r))
+}
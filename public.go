@@ -0,0 +1,37 @@
+package errorwrap
+
+type publicMessager interface {
+	PublicMessage() string
+}
+
+// GenericPublicMessage is returned by PublicMessage when there is no user-safe message in the error chain.
+var GenericPublicMessage = "an unexpected error occurred"
+
+// PublicMessage returns the user-safe message of err. It will find recursively from current level to the root of
+// ErrorWrapper stack and returns the first public message found, so the upper layers can override the message of the
+// lower layers. If there is no public message, GenericPublicMessage is returned.
+func PublicMessage(err error) string {
+	if msg := publicMessage(err); msg != "" {
+		return msg
+	}
+	return GenericPublicMessage
+}
+
+func publicMessage(err error) string {
+	for err != nil {
+		switch e := err.(type) {
+		case ErrorWrapper:
+			for _, curErr := range e.CurrentError() {
+				if msg := publicMessage(curErr); msg != "" {
+					return msg
+				}
+			}
+		case publicMessager:
+			if msg := e.PublicMessage(); msg != "" {
+				return msg
+			}
+		}
+		err = Unwrap(err)
+	}
+	return ""
+}
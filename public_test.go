@@ -0,0 +1,86 @@
+package errorwrap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorPublicDatabase = New("error database mysql", WithPublicMessage("service is temporarily unavailable"))
+	ErrorPublicNotFound = New("error user not found", WithPublicMessage("user not found"))
+)
+
+func TestPublicMessage(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "nil error",
+			args: args{err: nil},
+			want: GenericPublicMessage,
+		},
+		{
+			name: "standard error",
+			args: args{err: errors.New("dial tcp 10.0.0.1:3306")},
+			want: GenericPublicMessage,
+		},
+		{
+			name: "definition without public message",
+			args: args{err: ErrorMysqlDb},
+			want: GenericPublicMessage,
+		},
+		{
+			name: "definition with public message",
+			args: args{err: ErrorPublicDatabase},
+			want: "service is temporarily unavailable",
+		},
+		{
+			name: "root level public message",
+			args: args{err: Wrap(NewError(ErrorPublicDatabase), ErrorDomain)},
+			want: "service is temporarily unavailable",
+		},
+		{
+			name: "upper level overrides lower level",
+			args: args{err: Wrap(NewError(ErrorPublicDatabase), ErrorDomain, ErrorPublicNotFound)},
+			want: "user not found",
+		},
+		{
+			name: "definition wrapped by standard error",
+			args: args{err: fmt.Errorf("lookup: %w", ErrorPublicNotFound)},
+			want: "user not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PublicMessage(tt.args.err))
+		})
+	}
+}
+
+func TestPublicMessageKeepsInternalMessage(t *testing.T) {
+	err := Wrap(NewError(ErrorPublicDatabase), ErrorDomain)
+	assert.Equal(t, "error database mysql", ErrorPublicDatabase.Error())
+	assert.Contains(t, fmt.Sprintf("%+v", err), "error database mysql")
+	assert.NotContains(t, PublicMessage(err), "mysql")
+}
+
+// externalDefinition implements ErrorDefinition without a public message, as definitions outside this module do.
+type externalDefinition struct{}
+
+func (externalDefinition) Error() string { return "external definition" }
+
+func (d externalDefinition) Format(s fmt.State, verb rune) { formatMessage(s, verb, d.Error()) }
+
+func TestPublicMessageExternalDefinition(t *testing.T) {
+	var def ErrorDefinition = externalDefinition{}
+	err := NewError(def)
+	assert.Equal(t, GenericPublicMessage, PublicMessage(err))
+	assert.Equal(t, "request failed", PublicMessage(Wrap(err, New("error request", WithPublicMessage("request failed")))))
+}
@@ -0,0 +1,142 @@
+package errorwrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+var redactedText = "[REDACTED]"
+
+var (
+	// PatternEmail matches email addresses.
+	PatternEmail = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// PatternBearerToken matches bearer tokens, e.g. the value of an Authorization header.
+	PatternBearerToken = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+var (
+	redactionMu       sync.RWMutex
+	redactionPatterns []*regexp.Regexp
+	redactionFields   = map[string]struct{}{}
+)
+
+// RedactPatterns registers patterns that are masked in the ContextMessage of every ErrorWrapper when it is printed.
+// ContextMessage() itself keeps returning the original message.
+func RedactPatterns(patterns ...*regexp.Regexp) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+	for _, pattern := range patterns {
+		if pattern == nil {
+			continue
+		}
+		redactionPatterns = append(redactionPatterns, pattern)
+	}
+}
+
+// RedactFields registers field keys whose values are masked in the formatted output, the JSON output, and FieldsOf.
+func RedactFields(keys ...string) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+	for _, key := range keys {
+		redactionFields[key] = struct{}{}
+	}
+}
+
+// Redact masks the patterns registered by RedactPatterns in msg, like it is done for the ContextMessage of an
+// ErrorWrapper when it is printed. It is meant for integrations exporting ContextMessage() to other systems.
+func Redact(msg string) string {
+	return contextMessage(msg, true)
+}
+
+// Unsafe returns a formatter that prints err without any redaction. It must only be used for trusted outputs. The
+// returned value also implements json.Marshaler.
+//
+//	fmt.Printf("%+v\n", errorwrap.Unsafe(err))
+func Unsafe(err error) fmt.Formatter {
+	return &unsafeFormatter{err: err}
+}
+
+type unsafeFormatter struct {
+	err error
+}
+
+func (u *unsafeFormatter) Format(s fmt.State, verb rune) {
+	switch e := u.err.(type) {
+	case nil:
+		io.WriteString(s, "<nil>")
+	case *errorWrapper:
+		e.format(s, verb, false, nil)
+	case *errorDefinition, *templateDefinition, *templateError:
+		formatMessage(s, verb, errorMessage(e, false))
+	case fmt.Formatter:
+		e.Format(s, verb)
+	default:
+		formatMessage(s, verb, e.Error())
+	}
+}
+
+func (u *unsafeFormatter) MarshalJSON() ([]byte, error) {
+	if e, ok := u.err.(*errorWrapper); ok {
+		return e.marshalJSON(false)
+	}
+	if u.err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(errorMessage(u.err, false))
+}
+
+func formatMessage(s fmt.State, verb rune, msg string) {
+	switch verb {
+	case 'v', 's':
+		io.WriteString(s, msg)
+	case 'q':
+		fmt.Fprintf(s, "%q", msg)
+	}
+}
+
+// errorMessage returns the message of err. The internal messages of sensitive errors are only returned if redact is
+// false.
+func errorMessage(err error, redact bool) string {
+	if !redact {
+		switch e := err.(type) {
+		case *errorDefinition:
+			return e.msg
+		case *templateDefinition:
+			return e.msg
+		case *templateError:
+			return e.message(false)
+		case *errorWrapper:
+			return e.message(false)
+		}
+	}
+	return err.Error()
+}
+
+func contextMessage(msg string, redact bool) string {
+	if !redact {
+		return msg
+	}
+	redactionMu.RLock()
+	defer redactionMu.RUnlock()
+	for _, pattern := range redactionPatterns {
+		msg = pattern.ReplaceAllString(msg, redactedText)
+	}
+	return msg
+}
+
+func isRedactedField(key string) bool {
+	redactionMu.RLock()
+	defer redactionMu.RUnlock()
+	_, ok := redactionFields[key]
+	return ok
+}
+
+func fieldValue(key string, value interface{}, redact bool) interface{} {
+	if redact && isRedactedField(key) {
+		return redactedText
+	}
+	return value
+}
@@ -0,0 +1,90 @@
+package errorwrap
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+var (
+	ErrorSensitiveCredential = New("invalid password for user admin", Sensitive())
+)
+
+func setRedactPatterns(t *testing.T, patterns ...*regexp.Regexp) {
+	redactionMu.Lock()
+	old := redactionPatterns
+	redactionPatterns = nil
+	redactionMu.Unlock()
+	RedactPatterns(patterns...)
+	t.Cleanup(func() {
+		redactionMu.Lock()
+		redactionPatterns = old
+		redactionMu.Unlock()
+	})
+}
+
+func TestSensitiveDefinition(t *testing.T) {
+	err := Wrap(NewError(ErrorSensitiveCredential), ErrorDomain)
+
+	assert.Equal(t, redactedText, ErrorSensitiveCredential.Error())
+	assert.True(t, Is(err, ErrorSensitiveCredential))
+	for _, format := range []string{"%s", "%v", "%+s", "%+v", "%q"} {
+		assert.NotContains(t, fmt.Sprintf(format, err), "admin", format)
+	}
+	assert.NotContains(t, Wrapper(err, ErrorSensitiveCredential).Error(), "admin")
+
+	assert.Equal(t, "invalid password for user admin", fmt.Sprintf("%v", Unsafe(ErrorSensitiveCredential)))
+	assert.Contains(t, fmt.Sprintf("%+v", Unsafe(err)), "invalid password for user admin")
+	assert.Contains(t, fmt.Sprintf("%+v", Unsafe(err)), "error domain layer")
+}
+
+func TestRedactPatterns(t *testing.T) {
+	setRedactPatterns(t, PatternEmail, PatternBearerToken)
+
+	type args struct {
+		contextMessage string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "email",
+			args: args{contextMessage: "user john.doe@example.com not found"},
+			want: "user [REDACTED] not found",
+		},
+		{
+			name: "bearer token",
+			args: args{contextMessage: "unauthorized request with Bearer eyJhbGciOiJIUzI1NiJ9.e30.abc-_"},
+			want: "unauthorized request with [REDACTED]",
+		},
+		{
+			name: "nothing to redact",
+			args: args{contextMessage: "user 42 not found"},
+			want: "user 42 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewErrorWithMessage(tt.args.contextMessage, ErrorCommonNotFound)
+			assert.Contains(t, err.Error(), "context: "+tt.want)
+			assert.Contains(t, fmt.Sprintf("%+v", err), "context: "+tt.want)
+			assert.Contains(t, fmt.Sprintf("%+v", Unsafe(err)), "context: "+tt.args.contextMessage)
+			assert.Equal(t, tt.args.contextMessage, err.(ErrorWrapper).ContextMessage())
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	setRedactPatterns(t, PatternEmail)
+	assert.Equal(t, "find user [REDACTED]", Redact("find user john@example.com"))
+	assert.Equal(t, "find user 42", Redact("find user 42"))
+}
+
+func TestUnsafeStandardError(t *testing.T) {
+	assert.Equal(t, "error test b", fmt.Sprintf("%v", Unsafe(ErrorTestB)))
+	assert.Equal(t, `"error test b"`, fmt.Sprintf("%q", Unsafe(ErrorTestB)))
+	assert.Equal(t, "<nil>", fmt.Sprintf("%v", Unsafe(nil)))
+}
@@ -0,0 +1,43 @@
+package errorwrap
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	registryMu  sync.RWMutex
+	definitions = map[string]error{}
+)
+
+func init() {
+	RegisterDefinition(ErrorCommonNotFound, ErrorCommonPermissionDenied, ErrorCommonTimeout, ErrorCommonCanceled,
+		ErrorCommonUnavailable)
+}
+
+// RegisterDefinition registers ErrorDefinitions by their code, so they can be resolved back by DefinitionByCode, e.g.
+// when an error is decoded from another process. Definitions without code are ignored and a definition replaces the
+// one registered with the same code.
+func RegisterDefinition(defs ...error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, def := range defs {
+		if code := Code(def); code != "" {
+			definitions[code] = def
+		}
+	}
+}
+
+// DefinitionByCode returns the ErrorDefinition registered with code.
+func DefinitionByCode(code string) (error, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	def, ok := definitions[code]
+	return def, ok
+}
+
+// Hashable reports whether err can be used as a map key, e.g. of a registry keyed by ErrorDefinition. Errors of
+// uncomparable types, e.g. slice-backed multi-errors, panic when they are hashed. Hashable returns false for nil.
+func Hashable(err error) bool {
+	return err != nil && reflect.TypeOf(err).Comparable()
+}
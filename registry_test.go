@@ -0,0 +1,40 @@
+package errorwrap
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegisterDefinition(t *testing.T) {
+	def := New("error registry", WithCode("TEST_REGISTRY"))
+	template := NewTemplate("error registry {id}", WithCode("TEST_REGISTRY_TEMPLATE"))
+	RegisterDefinition(def, template, ErrorDomain, nil)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(definitions, "TEST_REGISTRY")
+		delete(definitions, "TEST_REGISTRY_TEMPLATE")
+		registryMu.Unlock()
+	})
+
+	got, ok := DefinitionByCode("TEST_REGISTRY")
+	assert.True(t, ok)
+	assert.Equal(t, def, got)
+
+	got, ok = DefinitionByCode("TEST_REGISTRY_TEMPLATE")
+	assert.True(t, ok)
+	assert.Equal(t, template, got)
+
+	got, ok = DefinitionByCode("NOT_FOUND")
+	assert.True(t, ok)
+	assert.Equal(t, ErrorCommonNotFound, got)
+
+	_, ok = DefinitionByCode("")
+	assert.False(t, ok)
+}
+
+func TestHashable(t *testing.T) {
+	assert.True(t, Hashable(ErrorDomain))
+	assert.True(t, Hashable(NewError(ErrorDomain)))
+	assert.False(t, Hashable(sliceError{ErrorDomain}))
+	assert.False(t, Hashable(nil))
+}
@@ -0,0 +1,398 @@
+// Package report submits errors to reporters, e.g. error trackers or log collectors.
+//
+// A Pipeline fans each submitted error out to its registered Reporters. It can sample the errors, limit the number of
+// reports of the same failure using errorwrap.Fingerprint, and deliver the reports asynchronously:
+//
+//	pipeline := report.New(report.WithSampleRate(0.5), report.WithRateLimit(10, time.Minute), report.WithAsync(1024))
+//	pipeline.Register(reporter)
+//	defer pipeline.Close(context.Background())
+//
+//	pipeline.Report(ctx, err)
+//
+// The package functions use the Default pipeline, which can also receive every new ErrorWrapper stack automatically
+// using EnableAutoReport.
+package report
+
+import (
+	"context"
+	"github.com/anantadwi13/errorwrap"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrorBufferFull     = errorwrap.New("report buffer is full", errorwrap.WithCategory(errorwrap.CategoryUnavailable))
+	ErrorPipelineClosed = errorwrap.New("report pipeline is closed", errorwrap.Permanent())
+)
+
+// Reporter receives the errors submitted to a Pipeline. The errors returned or created by a Reporter must not carry a
+// severity, since the errors with a severity are submitted again by EnableAutoReport, so a failing Reporter would
+// report its own failures endlessly.
+type Reporter interface {
+	Report(ctx context.Context, event *Event) error
+}
+
+// ReporterFunc adapts a function to a Reporter.
+type ReporterFunc func(ctx context.Context, event *Event) error
+
+func (f ReporterFunc) Report(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Event is an error submitted to a Pipeline.
+type Event struct {
+	Err         error
+	Fingerprint string
+	// Severity is the errorwrap.SeverityOf Err, or errorwrap.SeverityError if Err has no severity.
+	Severity errorwrap.Severity
+	Time     time.Time
+}
+
+// NewEvent creates the Event of err.
+func NewEvent(err error) *Event {
+	severity := errorwrap.SeverityOf(err)
+	if severity == errorwrap.SeverityUnknown {
+		severity = errorwrap.SeverityError
+	}
+	return &Event{
+		Err:         err,
+		Fingerprint: errorwrap.Fingerprint(err),
+		Severity:    severity,
+		Time:        time.Now(),
+	}
+}
+
+// Option configures a Pipeline created by New.
+type Option func(p *Pipeline)
+
+// WithSampleRate reports only a random fraction of the errors, rate being between 0 and 1.
+func WithSampleRate(rate float64) Option {
+	return func(p *Pipeline) {
+		p.sampleRate = rate
+	}
+}
+
+// WithRateLimit reports at most limit errors with the same fingerprint in each period.
+func WithRateLimit(limit int, period time.Duration) Option {
+	return func(p *Pipeline) {
+		p.rateLimit = limit
+		p.ratePeriod = period
+	}
+}
+
+// WithMinSeverity reports only the errors whose severity is at least severity.
+func WithMinSeverity(severity errorwrap.Severity) Option {
+	return func(p *Pipeline) {
+		p.minSeverity = severity
+	}
+}
+
+// WithAsync delivers the reports in the background. Up to bufferSize reports wait for the delivery, Report fails with
+// ErrorBufferFull when the buffer is full.
+func WithAsync(bufferSize int) Option {
+	return func(p *Pipeline) {
+		p.queue = make(chan queued, bufferSize)
+	}
+}
+
+// WithErrorHandler sets the function receiving the errors returned by the reporters when the reports are delivered
+// asynchronously. They are discarded by default.
+func WithErrorHandler(handler func(err error)) Option {
+	return func(p *Pipeline) {
+		p.errorHandler = handler
+	}
+}
+
+type queued struct {
+	ctx   context.Context
+	event *Event
+	// flushed is closed once every report queued before is delivered.
+	flushed chan struct{}
+}
+
+// detachedContext keeps the values of a request context without its cancellation, since the asynchronous delivery
+// outlives the request.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Pipeline fans the submitted errors out to its Reporters.
+type Pipeline struct {
+	sampleRate   float64
+	rateLimit    int
+	ratePeriod   time.Duration
+	minSeverity  errorwrap.Severity
+	errorHandler func(err error)
+
+	mu        sync.RWMutex
+	reporters []Reporter
+
+	rateMu    sync.Mutex
+	windows   map[string]*window
+	lastPrune time.Time
+
+	queue     chan queued
+	queueMu   sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// now and random are replaced in tests.
+	now    func() time.Time
+	random func() float64
+}
+
+// New creates a Pipeline. By default, every error is reported synchronously.
+func New(opts ...Option) *Pipeline {
+	p := &Pipeline{
+		sampleRate: 1,
+		windows:    map[string]*window{},
+		done:       make(chan struct{}),
+		now:        time.Now,
+		random:     rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.queue != nil {
+		go p.run()
+	} else {
+		close(p.done)
+	}
+	return p
+}
+
+// Register registers reporters into p.
+func (p *Pipeline) Register(reporter ...Reporter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range reporter {
+		if r == nil {
+			continue
+		}
+		p.reporters = append(p.reporters, r)
+	}
+}
+
+// Report submits err to every Reporter of p. Errors that are filtered out by the severity, sampled out or rate limited
+// are dropped silently. When p is synchronous, Report returns the errors returned by the reporters, placed in the same
+// level. If err is nil then Report does nothing.
+func (p *Pipeline) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	event := NewEvent(err)
+	if !p.accept(event) {
+		return nil
+	}
+
+	if p.queue == nil {
+		return p.deliver(ctx, event)
+	}
+
+	p.queueMu.RLock()
+	defer p.queueMu.RUnlock()
+	if p.closed {
+		return errorwrap.NewError(ErrorPipelineClosed)
+	}
+	select {
+	case p.queue <- queued{ctx: detachedContext{ctx}, event: event}:
+		return nil
+	default:
+		return errorwrap.NewError(ErrorBufferFull)
+	}
+}
+
+// Flush waits until the reports submitted before are delivered, or until ctx is done.
+func (p *Pipeline) Flush(ctx context.Context) error {
+	if p.queue == nil {
+		return nil
+	}
+
+	p.queueMu.RLock()
+	if p.closed {
+		p.queueMu.RUnlock()
+		return p.wait(ctx, p.done)
+	}
+	flushed := make(chan struct{})
+	select {
+	case p.queue <- queued{flushed: flushed}:
+		p.queueMu.RUnlock()
+	case <-ctx.Done():
+		p.queueMu.RUnlock()
+		return errorwrap.Wrap(ctx.Err(), errorwrap.ErrorCommonCanceled)
+	}
+	return p.wait(ctx, flushed)
+}
+
+// Close stops accepting reports, then waits until the pending reports are delivered, or until ctx is done. It should
+// be called on shutdown.
+func (p *Pipeline) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		if p.queue == nil {
+			return
+		}
+		p.queueMu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.queueMu.Unlock()
+	})
+	return p.wait(ctx, p.done)
+}
+
+func (p *Pipeline) wait(ctx context.Context, ch chan struct{}) error {
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return errorwrap.Wrap(ctx.Err(), errorwrap.ErrorCommonCanceled)
+	}
+}
+
+func (p *Pipeline) run() {
+	defer close(p.done)
+	for q := range p.queue {
+		if q.flushed != nil {
+			close(q.flushed)
+			continue
+		}
+		if err := p.deliver(q.ctx, q.event); err != nil && p.errorHandler != nil {
+			p.errorHandler(err)
+		}
+	}
+}
+
+func (p *Pipeline) deliver(ctx context.Context, event *Event) error {
+	p.mu.RLock()
+	reporters := p.reporters
+	p.mu.RUnlock()
+
+	var errs []error
+	for _, r := range reporters {
+		if err := r.Report(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errorwrap.NewError(errs...)
+}
+
+func (p *Pipeline) accept(event *Event) bool {
+	if event.Severity < p.minSeverity {
+		return false
+	}
+	if p.sampleRate < 1 && p.random() >= p.sampleRate {
+		return false
+	}
+	if p.rateLimit <= 0 {
+		return true
+	}
+
+	p.rateMu.Lock()
+	defer p.rateMu.Unlock()
+	now := p.now()
+	if now.Sub(p.lastPrune) >= p.ratePeriod {
+		for fingerprint, w := range p.windows {
+			if now.Sub(w.start) >= p.ratePeriod {
+				delete(p.windows, fingerprint)
+			}
+		}
+		p.lastPrune = now
+	}
+
+	w, ok := p.windows[event.Fingerprint]
+	if !ok || now.Sub(w.start) >= p.ratePeriod {
+		w = &window{start: now}
+		p.windows[event.Fingerprint] = w
+	}
+	if w.count >= p.rateLimit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultPipeline = New()
+
+	// autoReportMin is the minimum severity reported automatically, or errorwrap.SeverityUnknown if disabled.
+	autoReportMin int32
+)
+
+func init() {
+	errorwrap.RegisterCreationHook(func(err error) {
+		min := errorwrap.Severity(atomic.LoadInt32(&autoReportMin))
+		if min == errorwrap.SeverityUnknown {
+			return
+		}
+		severity := errorwrap.SeverityOf(err)
+		if severity == errorwrap.SeverityUnknown || severity < min {
+			return
+		}
+		Default().Report(context.Background(), err)
+	})
+}
+
+// Default returns the Pipeline used by the package functions.
+func Default() *Pipeline {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultPipeline
+}
+
+// SetDefault replaces the Pipeline used by the package functions.
+func SetDefault(p *Pipeline) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultPipeline = p
+}
+
+// Register registers reporters into the Default pipeline.
+func Register(reporter ...Reporter) {
+	Default().Register(reporter...)
+}
+
+// Report submits err to the Default pipeline.
+func Report(ctx context.Context, err error) error {
+	return Default().Report(ctx, err)
+}
+
+// Flush waits until the reports submitted to the Default pipeline are delivered.
+func Flush(ctx context.Context) error {
+	return Default().Flush(ctx)
+}
+
+// EnableAutoReport submits every new ErrorWrapper stack whose severity is at least min to the Default pipeline, as
+// soon as it is created by errorwrap. Errors without severity are not submitted, which is why the definitions of this
+// package and of the Reporters carry none. Since the severity is only known at creation, it should be set on the
+// definitions placed in the root level.
+func EnableAutoReport(min errorwrap.Severity) {
+	if min == errorwrap.SeverityUnknown {
+		min = errorwrap.SeverityDebug
+	}
+	atomic.StoreInt32(&autoReportMin, int32(min))
+}
+
+// DisableAutoReport stops EnableAutoReport.
+func DisableAutoReport() {
+	atomic.StoreInt32(&autoReportMin, int32(errorwrap.SeverityUnknown))
+}
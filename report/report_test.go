@@ -0,0 +1,291 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	ErrorTestA     = errorwrap.New("error test a")
+	ErrorTestFatal = errorwrap.New("error test fatal", errorwrap.WithSeverity(errorwrap.SeverityFatal))
+	ErrorTestDebug = errorwrap.New("error test debug", errorwrap.WithSeverity(errorwrap.SeverityDebug))
+	ErrorReporter  = errorwrap.New("error reporter")
+)
+
+func newTestError(i int) error {
+	return errorwrap.NewErrorWithMessage("attempt "+strconv.Itoa(i), ErrorTestA)
+}
+
+func TestPipeline_Report(t *testing.T) {
+	type args struct {
+		opts []Option
+		errs []error
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantEvents int
+	}{
+		{
+			name:       "nil error",
+			args:       args{errs: []error{nil}},
+			wantEvents: 0,
+		},
+		{
+			name:       "every error",
+			args:       args{errs: []error{newTestError(1), newTestError(2), errors.New("foreign")}},
+			wantEvents: 3,
+		},
+		{
+			name: "min severity",
+			args: args{
+				opts: []Option{WithMinSeverity(errorwrap.SeverityError)},
+				errs: []error{errorwrap.NewError(ErrorTestDebug), errorwrap.NewError(ErrorTestFatal), newTestError(1)},
+			},
+			wantEvents: 2,
+		},
+		{
+			name: "rate limit per fingerprint",
+			args: args{
+				opts: []Option{WithRateLimit(2, time.Minute)},
+				errs: []error{newTestError(1), newTestError(2), newTestError(3), errorwrap.NewError(ErrorTestFatal)},
+			},
+			wantEvents: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewMemorySink()
+			p := New(tt.args.opts...)
+			p.Register(sink)
+			for _, err := range tt.args.errs {
+				assert.NoError(t, p.Report(context.Background(), err))
+			}
+			assert.Len(t, sink.Events(), tt.wantEvents)
+		})
+	}
+}
+
+func TestPipeline_Report_FanOut(t *testing.T) {
+	sinkA, sinkB := NewMemorySink(), NewMemorySink()
+	failing := ReporterFunc(func(ctx context.Context, event *Event) error {
+		return errorwrap.NewError(ErrorReporter)
+	})
+
+	p := New()
+	p.Register(sinkA, nil, failing, sinkB)
+
+	err := p.Report(context.Background(), errorwrap.NewError(ErrorTestFatal))
+	assert.True(t, errorwrap.Is(err, ErrorReporter))
+	if assert.Len(t, sinkA.Events(), 1) {
+		event := sinkA.Events()[0]
+		assert.True(t, errorwrap.Is(event.Err, ErrorTestFatal))
+		assert.Equal(t, errorwrap.SeverityFatal, event.Severity)
+		assert.Equal(t, errorwrap.Fingerprint(event.Err), event.Fingerprint)
+		assert.False(t, event.Time.IsZero())
+	}
+	assert.Equal(t, sinkA.Events(), sinkB.Events())
+
+	sinkA.Reset()
+	assert.Empty(t, sinkA.Events())
+}
+
+func TestPipeline_Report_Sampling(t *testing.T) {
+	sink := NewMemorySink()
+	p := New(WithSampleRate(0.5))
+	p.Register(sink)
+
+	randoms := []float64{0.1, 0.7, 0.49, 0.5}
+	p.random = func() float64 {
+		r := randoms[0]
+		randoms = randoms[1:]
+		return r
+	}
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, p.Report(context.Background(), newTestError(i)))
+	}
+	assert.Len(t, sink.Events(), 2)
+}
+
+func TestPipeline_Report_RateLimitWindow(t *testing.T) {
+	sink := NewMemorySink()
+	p := New(WithRateLimit(1, time.Minute))
+	p.Register(sink)
+
+	now := time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	report := func() {
+		assert.NoError(t, p.Report(context.Background(), newTestError(1)))
+	}
+	report()
+	report()
+	assert.Len(t, sink.Events(), 1)
+
+	now = now.Add(time.Minute)
+	report()
+	report()
+	assert.Len(t, sink.Events(), 2)
+	assert.Len(t, p.windows, 1)
+}
+
+func TestPipeline_Async(t *testing.T) {
+	sink := NewMemorySink()
+	release := make(chan struct{})
+	blocking := ReporterFunc(func(ctx context.Context, event *Event) error {
+		<-release
+		return nil
+	})
+
+	var mu sync.Mutex
+	var handled []error
+	p := New(WithAsync(2), WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, err)
+	}))
+	p.Register(blocking, sink, ReporterFunc(func(ctx context.Context, event *Event) error {
+		return errorwrap.NewError(ErrorReporter)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, p.Report(ctx, newTestError(1)))
+	cancel()
+
+	// the first report is held by the blocking reporter, the next ones fill the buffer
+	assert.Eventually(t, func() bool { return len(p.queue) == 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, p.Report(context.Background(), newTestError(2)))
+	assert.NoError(t, p.Report(context.Background(), newTestError(3)))
+	assert.True(t, errorwrap.Is(p.Report(context.Background(), newTestError(4)), ErrorBufferFull))
+
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer flushCancel()
+	assert.True(t, errorwrap.Is(p.Flush(flushCtx), errorwrap.ErrorCommonCanceled))
+
+	close(release)
+	assert.NoError(t, p.Flush(context.Background()))
+	assert.Len(t, sink.Events(), 3)
+
+	assert.NoError(t, p.Report(context.Background(), newTestError(5)))
+	assert.NoError(t, p.Close(context.Background()))
+	assert.NoError(t, p.Close(context.Background()))
+	assert.Len(t, sink.Events(), 4)
+	assert.True(t, errorwrap.Is(p.Report(context.Background(), newTestError(6)), ErrorPipelineClosed))
+	assert.NoError(t, p.Flush(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, handled, 4)
+}
+
+func TestEnableAutoReport(t *testing.T) {
+	sink := NewMemorySink()
+	defer SetDefault(Default())
+	SetDefault(New())
+	Register(sink)
+	Register(ReporterFunc(func(ctx context.Context, event *Event) error {
+		// the errors of the reporters carry no severity, so they are not reported automatically
+		return errorwrap.NewError(ErrorReporter)
+	}))
+
+	errorwrap.NewError(ErrorTestFatal)
+	assert.Empty(t, sink.Events())
+
+	EnableAutoReport(errorwrap.SeverityError)
+	defer DisableAutoReport()
+
+	errorwrap.NewError(ErrorTestFatal)
+	errorwrap.NewError(ErrorTestDebug)
+	errorwrap.Wrap(errorwrap.NewError(ErrorTestA), ErrorTestFatal)
+	errorwrap.Wrap(errors.New("foreign"), ErrorTestFatal)
+	if assert.Len(t, sink.Events(), 2) {
+		assert.True(t, errorwrap.Is(sink.Events()[0].Err, ErrorTestFatal))
+		assert.True(t, errorwrap.Is(sink.Events()[1].Err, ErrorTestFatal))
+	}
+
+	DisableAutoReport()
+	errorwrap.NewError(ErrorTestFatal)
+	assert.Len(t, sink.Events(), 2)
+
+	assert.True(t, errorwrap.Is(Report(context.Background(), newTestError(1)), ErrorReporter))
+	assert.NoError(t, Flush(context.Background()))
+	assert.Len(t, sink.Events(), 3)
+}
+
+func TestAutoReportFailingReporter(t *testing.T) {
+	type args struct {
+		async     bool
+		goroutine bool
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{name: "sync", args: args{}},
+		{name: "async", args: args{async: true}},
+		{name: "created in a goroutine of the reporter", args: args{goroutine: true}},
+		{name: "async, created in a goroutine of the reporter", args: args{async: true, goroutine: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.args.async {
+				opts = append(opts, WithAsync(16))
+			}
+			defer SetDefault(Default())
+			SetDefault(New(opts...))
+			var calls int32
+			Register(ReporterFunc(func(ctx context.Context, event *Event) error {
+				// stop failing eventually, so a regression fails the test instead of recursing endlessly
+				if atomic.AddInt32(&calls, 1) > 10 {
+					return nil
+				}
+				if !tt.args.goroutine {
+					return errorwrap.NewError(ErrorReporter)
+				}
+				created := make(chan error)
+				go func() {
+					created <- errorwrap.NewError(ErrorReporter)
+				}()
+				return <-created
+			}), ReporterFunc(func(ctx context.Context, event *Event) error {
+				return errorwrap.NewError(ErrorReporter)
+			}))
+			EnableAutoReport(errorwrap.SeverityDebug)
+			defer DisableAutoReport()
+
+			errorwrap.NewError(ErrorTestFatal)
+			assert.NoError(t, Flush(context.Background()))
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestDefinitionsWithoutSeverity(t *testing.T) {
+	// the errors of the pipeline and the reporters must not be reported automatically
+	for _, def := range []error{ErrorBufferFull, ErrorPipelineClosed, ErrorWriteSink} {
+		assert.Equal(t, errorwrap.SeverityUnknown, errorwrap.SeverityOf(def), def)
+	}
+
+	p := New()
+	p.Register(ReporterFunc(func(ctx context.Context, event *Event) error {
+		return errorwrap.NewError(ErrorReporter)
+	}), NewWriterSink(failingWriter{}))
+	err := p.Report(context.Background(), errorwrap.NewError(ErrorTestFatal))
+	assert.True(t, errorwrap.Is(err, ErrorWriteSink))
+	assert.Equal(t, errorwrap.SeverityUnknown, errorwrap.SeverityOf(err))
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/anantadwi13/errorwrap"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrorWriteSink = errorwrap.New("unable to write report")
+)
+
+// MemorySink is a Reporter keeping the events in memory. It is meant for tests.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+// NewMemorySink creates a MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Report(ctx context.Context, event *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+// Events returns the reported events, in the order of delivery.
+func (s *MemorySink) Events() []*Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]*Event, len(s.events))
+	copy(events, s.events)
+	return events
+}
+
+// Reset removes the reported events.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+}
+
+// WriterSink is a Reporter writing each event as a JSON line:
+//
+//	{"time":"...","fingerprint":"...","severity":"error","message":"...","error":{"levels":[...]}}
+//
+// The error is written using its MarshalJSON method if it has one, so the sensitive data are redacted.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a WriterSink writing into w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// OpenFileSink creates a WriterSink appending into the file name. The file is created if it does not exist.
+func OpenFileSink(name string) (*WriterSink, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, errorwrap.WrapWithMessage(err, name, ErrorWriteSink)
+	}
+	return NewWriterSink(f), nil
+}
+
+type writerEvent struct {
+	Time        time.Time      `json:"time"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Message     string         `json:"message"`
+	Error       json.Marshaler `json:"error,omitempty"`
+}
+
+func (s *WriterSink) Report(ctx context.Context, event *Event) error {
+	we := writerEvent{
+		Time:        event.Time,
+		Fingerprint: event.Fingerprint,
+		Severity:    event.Severity.String(),
+		Message:     event.Err.Error(),
+	}
+	if m, ok := event.Err.(json.Marshaler); ok {
+		we.Error = m
+	}
+	line, err := json.Marshal(we)
+	if err != nil {
+		return errorwrap.Wrap(err, ErrorWriteSink)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return errorwrap.Wrap(err, ErrorWriteSink)
+	}
+	return nil
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (s *WriterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterSink_Report(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantError bool
+	}{
+		{name: "error wrapper", args: args{err: errorwrap.AppendFields(newTestError(1), errorwrap.Fields{"id": 1})}, wantError: true},
+		{name: "foreign error", args: args{err: errors.New("foreign")}, wantError: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			sink := NewWriterSink(buf)
+			event := NewEvent(tt.args.err)
+			event.Time = time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC)
+
+			assert.NoError(t, sink.Report(context.Background(), event))
+			assert.True(t, strings.HasSuffix(buf.String(), "}\n"))
+
+			var got map[string]interface{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, "2022-01-02T15:04:05Z", got["time"])
+			assert.Equal(t, event.Fingerprint, got["fingerprint"])
+			assert.Equal(t, "error", got["severity"])
+			assert.Equal(t, tt.args.err.Error(), got["message"])
+			_, ok := got["error"]
+			assert.Equal(t, tt.wantError, ok)
+			assert.NoError(t, sink.Close())
+		})
+	}
+}
+
+func TestOpenFileSink(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "errors.jsonl")
+
+	for i := 0; i < 2; i++ {
+		sink, err := OpenFileSink(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, sink.Report(context.Background(), NewEvent(newTestError(i))))
+		assert.NoError(t, sink.Close())
+	}
+
+	content, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+
+	_, err = OpenFileSink(filepath.Join(name, "invalid"))
+	assert.True(t, errorwrap.Is(err, ErrorWriteSink))
+}
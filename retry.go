@@ -0,0 +1,210 @@
+package errorwrap
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var (
+	// ErrorRetryFailed is placed in the level added by Retry on top of the error of the last attempt.
+	ErrorRetryFailed = New("error retry failed")
+)
+
+// RetryAttempts is the detail placed by Retry when it fails. It contains the errors of the attempts before the last
+// one, from the first attempt.
+type RetryAttempts []error
+
+type retryState int8
+
+const (
+	retryUnset retryState = iota
+	retryRetryable
+	retryPermanent
+)
+
+type retryMark struct {
+	state retryState
+	after time.Duration
+}
+
+// Retryable marks an ErrorDefinition as retryable.
+func Retryable() DefinitionOption {
+	return func(d *errorDefinition) {
+		d.retry.state = retryRetryable
+	}
+}
+
+// Permanent marks an ErrorDefinition as permanent, so Retry stops immediately when it meets the definition.
+func Permanent() DefinitionOption {
+	return func(d *errorDefinition) {
+		d.retry = retryMark{state: retryPermanent}
+	}
+}
+
+// WithRetryAfter marks an ErrorDefinition as retryable with a hint of the minimum delay before the next attempt.
+func WithRetryAfter(retryAfter time.Duration) DefinitionOption {
+	return func(d *errorDefinition) {
+		d.retry = retryMark{state: retryRetryable, after: retryAfter}
+	}
+}
+
+// MarkRetryable marks the current level of errWrapper as retryable. retryAfter is a hint of the minimum delay before
+// the next attempt, pass 0 if there is no hint. It will return the same errWrapper instance or new instance if
+// errWrapper is not an ErrorWrapper. If errWrapper is nil then MarkRetryable returns nil.
+func MarkRetryable(errWrapper error, retryAfter time.Duration) error {
+	return markRetry(errWrapper, callStack(), retryMark{state: retryRetryable, after: retryAfter})
+}
+
+// MarkPermanent marks the current level of errWrapper as permanent. It will return the same errWrapper instance or new
+// instance if errWrapper is not an ErrorWrapper. If errWrapper is nil then MarkPermanent returns nil.
+func MarkPermanent(errWrapper error) error {
+	return markRetry(errWrapper, callStack(), retryMark{state: retryPermanent})
+}
+
+// markRetry implements MarkRetryable and MarkPermanent. st is the stack of the level created if errWrapper is not an
+// ErrorWrapper.
+func markRetry(errWrapper error, st *stack, mark retryMark) error {
+	if errWrapper == nil {
+		return nil
+	}
+	ew, ok := errWrapper.(*errorWrapper)
+	if !ok || ew == nil {
+		ew = newRootWrapper(st, errWrapper)
+		if ew == nil {
+			return nil
+		}
+	}
+	ew.retry = mark
+	return ew
+}
+
+// IsRetryable checks whether err is marked as retryable. It will find recursively from current level to the root of
+// ErrorWrapper stack and the first explicit marking wins. In each level the marking of the level is checked before
+// the markings of the CurrentError entries. If there is no marking, IsRetryable returns false.
+func IsRetryable(err error) bool {
+	return retryMarkOf(err).state == retryRetryable
+}
+
+// IsPermanent checks whether err is marked as permanent, following the same rule as IsRetryable.
+func IsPermanent(err error) bool {
+	return retryMarkOf(err).state == retryPermanent
+}
+
+// RetryAfter returns the retry-after hint of err if err is retryable and has a hint.
+func RetryAfter(err error) (time.Duration, bool) {
+	mark := retryMarkOf(err)
+	if mark.state != retryRetryable || mark.after <= 0 {
+		return 0, false
+	}
+	return mark.after, true
+}
+
+func retryMarkOf(err error) retryMark {
+	for ; err != nil; err = Unwrap(err) {
+		if ew, ok := err.(*errorWrapper); ok && ew != nil {
+			if ew.retry.state != retryUnset {
+				return ew.retry
+			}
+			for _, curErr := range ew.errors {
+				if mark := retryMarkOf(curErr); mark.state != retryUnset {
+					return mark
+				}
+			}
+			continue
+		}
+		if def := definitionOf(err); def != nil && def.retry.state != retryUnset {
+			return def.retry
+		}
+	}
+	return retryMark{}
+}
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first one. Values less than 1 are treated as 1.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the delay after each attempt. Values less than 1 are treated as 1.
+	Multiplier float64
+	// Jitter randomizes the delay by up to the given fraction, e.g. 0.2 means +/- 20%.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a RetryPolicy with exponential backoff and jitter.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := math.Max(p.Multiplier, 1)
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 - p.Jitter + 2*p.Jitter*rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// Retry calls fn until it succeeds, fn returns an error marked as permanent, policy.MaxAttempts is reached, or ctx is
+// done. The delay before the next attempt is the backoff of policy or the retry-after hint of the error, whichever is
+// longer. Errors that are not marked at all are retried.
+//
+// When Retry fails, it wraps the error of the last attempt in a level containing ErrorRetryFailed, whose
+// ContextMessage is "attempt N", so the levels and the stack trace of the last error are kept. The errors of the
+// previous attempts are placed in the same level as a RetryAttempts detail. If ctx is done while waiting, ctx.Err() is
+// placed in the level as well and the ContextMessage is "retry aborted after attempt N".
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var attempts RetryAttempts
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= maxAttempts || IsPermanent(err) {
+			return retryFailed(err, fmt.Sprintf("attempt %d", attempt), callStack(), attempts)
+		}
+
+		delay := policy.backoff(attempt)
+		if retryAfter, ok := RetryAfter(err); ok && retryAfter > delay {
+			delay = retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retryFailed(err, fmt.Sprintf("retry aborted after attempt %d", attempt), callStack(), attempts,
+				ctx.Err())
+		case <-timer.C:
+		}
+		attempts = append(attempts, err)
+	}
+}
+
+// retryFailed wraps last, the error of the last attempt of Retry, in a level containing ErrorRetryFailed and err. st
+// is the stack of the level and attempts are the errors of the previous attempts.
+func retryFailed(last error, contextMessage string, st *stack, attempts RetryAttempts, err ...error) error {
+	errWrap := wrapWithMessage(last, contextMessage, st, append([]error{ErrorRetryFailed}, err...)...)
+	if len(attempts) > 0 {
+		errWrap = AppendDetails(errWrap, attempts)
+	}
+	return errWrap
+}
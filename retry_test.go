@@ -0,0 +1,227 @@
+package errorwrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	ErrorRetryTimeout   = New("error timeout", Retryable())
+	ErrorRetryRateLimit = New("error rate limit", WithRetryAfter(20*time.Millisecond))
+	ErrorRetryInvalid   = New("error invalid argument", Permanent())
+)
+
+func TestIsRetryable(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantRetryable bool
+		wantPermanent bool
+		wantAfter     time.Duration
+	}{
+		{
+			name: "nil error",
+			args: args{err: nil},
+		},
+		{
+			name: "unmarked error",
+			args: args{err: appLayer()},
+		},
+		{
+			name:          "retryable definition",
+			args:          args{err: ErrorRetryTimeout},
+			wantRetryable: true,
+		},
+		{
+			name:          "retryable definition with hint in root level",
+			args:          args{err: Wrap(NewError(ErrorRetryRateLimit), ErrorDomain)},
+			wantRetryable: true,
+			wantAfter:     20 * time.Millisecond,
+		},
+		{
+			name:          "upper level definition wins",
+			args:          args{err: Wrap(NewError(ErrorRetryTimeout), ErrorRetryInvalid)},
+			wantPermanent: true,
+		},
+		{
+			name:          "level marking wins over definition",
+			args:          args{err: MarkPermanent(NewError(ErrorRetryTimeout))},
+			wantPermanent: true,
+		},
+		{
+			name:          "level marking with hint",
+			args:          args{err: Wrap(MarkRetryable(errors.New("standard error"), time.Second), ErrorDomain)},
+			wantRetryable: true,
+			wantAfter:     time.Second,
+		},
+		{
+			name:          "nested wrapper entry",
+			args:          args{err: NewError(Wrap(NewError(ErrorRetryTimeout), ErrorDomain))},
+			wantRetryable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantRetryable, IsRetryable(tt.args.err))
+			assert.Equal(t, tt.wantPermanent, IsPermanent(tt.args.err))
+			after, ok := RetryAfter(tt.args.err)
+			assert.Equal(t, tt.wantAfter, after)
+			assert.Equal(t, tt.wantAfter > 0, ok)
+		})
+	}
+}
+
+func TestMarkRetryable(t *testing.T) {
+	assert.Nil(t, MarkRetryable(nil, 0))
+	assert.Nil(t, MarkPermanent(nil))
+
+	err := NewError(ErrorInfraDatabase)
+	assert.Equal(t, err, MarkRetryable(err, 0))
+	assert.True(t, IsRetryable(err))
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	Multiplier:     2,
+	Jitter:         0.5,
+}
+
+func TestRetry(t *testing.T) {
+	type args struct {
+		errs []error
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantCalls    int
+		wantNil      bool
+		wantContexts []string
+		wantAttempts RetryAttempts
+	}{
+		{
+			name:      "success at first attempt",
+			args:      args{errs: []error{nil}},
+			wantCalls: 1,
+			wantNil:   true,
+		},
+		{
+			name:      "success after retry",
+			args:      args{errs: []error{ErrorRetryTimeout, errors.New("unmarked"), nil}},
+			wantCalls: 3,
+			wantNil:   true,
+		},
+		{
+			name:         "max attempts",
+			args:         args{errs: []error{ErrorRetryTimeout, ErrorRetryTimeout, NewError(ErrorTestA), nil}},
+			wantCalls:    3,
+			wantContexts: []string{"attempt 3", ""},
+			wantAttempts: RetryAttempts{ErrorRetryTimeout, ErrorRetryTimeout},
+		},
+		{
+			name:         "stop on permanent error",
+			args:         args{errs: []error{ErrorRetryTimeout, Wrap(NewError(ErrorRetryInvalid), ErrorDomain), nil}},
+			wantCalls:    2,
+			wantContexts: []string{"attempt 2", "", ""},
+			wantAttempts: RetryAttempts{ErrorRetryTimeout},
+		},
+		{
+			name:         "single attempt",
+			args:         args{errs: []error{ErrorRetryInvalid}},
+			wantCalls:    1,
+			wantContexts: []string{"attempt 1", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			got := Retry(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+				err := tt.args.errs[calls]
+				calls++
+				return err
+			})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantNil {
+				assert.Nil(t, got)
+				return
+			}
+
+			var contexts []string
+			for err := got; err != nil; err = Unwrap(err) {
+				contexts = append(contexts, err.(ErrorWrapper).ContextMessage())
+			}
+			assert.Equal(t, tt.wantContexts, contexts)
+			assert.True(t, IsExact(got, ErrorRetryFailed))
+			assert.True(t, Is(got, tt.args.errs[calls-1]))
+
+			var attempts RetryAttempts
+			assert.Equal(t, tt.wantAttempts != nil, Detail(got, &attempts))
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryRetryAfterHint(t *testing.T) {
+	start := time.Now()
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{MaxAttempts: 2}, func(ctx context.Context) error {
+		calls++
+		return ErrorRetryRateLimit
+	})
+	assert.Equal(t, 2, calls)
+	assert.True(t, Is(err, ErrorRetryRateLimit))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+}
+
+func TestRetryContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}, func(ctx context.Context) error {
+		cancel()
+		return ErrorRetryTimeout
+	})
+	assert.True(t, Is(err, context.Canceled))
+	assert.True(t, Is(err, ErrorRetryTimeout))
+	assert.True(t, IsExact(err, ErrorRetryFailed))
+	assert.Equal(t, "retry aborted after attempt 1", err.(ErrorWrapper).ContextMessage())
+}
+
+func TestRetryFormat(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+		calls++
+		if calls < 2 {
+			return ErrorRetryTimeout
+		}
+		return domainLayer(MYSQL)
+	})
+	assert.Equal(t, 3, calls)
+
+	got := fmt.Sprintf("%+v", Normalized(err, GoldenOptions))
+	assert.True(t, strings.HasPrefix(got, " -  error retry failed\n    context: attempt 3\n -  error domain layer\n"+
+		" -  error infra layer\n    error not found\n    error database mysql\n\n"), got)
+	assert.Contains(t, got, "github.com/anantadwi13/errorwrap.infraDbLayer\n")
+	assert.Equal(t, OriginLayer(domainLayer(MYSQL)), OriginLayer(err))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Multiplier: 3}
+	assert.Equal(t, 10*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 30*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(3))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(1)
+		assert.GreaterOrEqual(t, int64(delay), int64(5*time.Millisecond))
+		assert.LessOrEqual(t, int64(delay), int64(15*time.Millisecond))
+	}
+}
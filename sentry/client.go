@@ -0,0 +1,213 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/anantadwi13/errorwrap/report"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clientName is sent in the X-Sentry-Auth header.
+const clientName = "errorwrap-sentry/1.0"
+
+var (
+	ErrorInvalidDSN = errorwrap.New("invalid sentry dsn", errorwrap.WithCategory(errorwrap.CategoryInvalidArgument))
+	ErrorSend       = errorwrap.New("unable to send sentry event", errorwrap.WithCategory(errorwrap.CategoryUnavailable))
+	ErrorRejected   = errorwrap.New("sentry event rejected", errorwrap.Permanent())
+)
+
+// DSN is a parsed Sentry DSN, e.g. https://public@sentry.example.com/1.
+type DSN struct {
+	Scheme    string
+	PublicKey string
+	SecretKey string
+	Host      string
+	// Path is the path prefix of the Sentry server, without the project id.
+	Path      string
+	ProjectID string
+}
+
+// ParseDSN parses a Sentry DSN.
+func ParseDSN(dsn string) (*DSN, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, errorwrap.Wrap(err, ErrorInvalidDSN)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errorwrap.NewErrorWithMessage("unsupported scheme "+u.Scheme, ErrorInvalidDSN)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errorwrap.NewErrorWithMessage("missing public key", ErrorInvalidDSN)
+	}
+	if u.Host == "" {
+		return nil, errorwrap.NewErrorWithMessage("missing host", ErrorInvalidDSN)
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	projectID := path[i+1:]
+	if projectID == "" {
+		return nil, errorwrap.NewErrorWithMessage("missing project id", ErrorInvalidDSN)
+	}
+
+	secret, _ := u.User.Password()
+	return &DSN{
+		Scheme:    u.Scheme,
+		PublicKey: u.User.Username(),
+		SecretKey: secret,
+		Host:      u.Host,
+		Path:      path[:i],
+		ProjectID: projectID,
+	}, nil
+}
+
+// StoreURL returns the URL of the store endpoint receiving the events.
+func (d *DSN) StoreURL() string {
+	return fmt.Sprintf("%s://%s%s/api/%s/store/", d.Scheme, d.Host, d.Path, d.ProjectID)
+}
+
+// AuthHeader returns the value of the X-Sentry-Auth header.
+func (d *DSN) AuthHeader() string {
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, d.PublicKey)
+	if d.SecretKey != "" {
+		auth += ", sentry_secret=" + d.SecretKey
+	}
+	return auth
+}
+
+// Option configures a Client created by NewClient.
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client sending the events. http.DefaultClient is used by default.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithEnvironment sets the environment of the events, e.g. "production".
+func WithEnvironment(environment string) Option {
+	return func(c *Client) {
+		c.environment = environment
+	}
+}
+
+// WithRelease sets the release of the events, e.g. the version of the application.
+func WithRelease(release string) Option {
+	return func(c *Client) {
+		c.release = release
+	}
+}
+
+// WithServerName sets the server name of the events.
+func WithServerName(serverName string) Option {
+	return func(c *Client) {
+		c.serverName = serverName
+	}
+}
+
+// Client sends events to the store endpoint of a Sentry DSN.
+type Client struct {
+	dsn         *DSN
+	httpClient  *http.Client
+	environment string
+	release     string
+	serverName  string
+}
+
+var _ report.Reporter = (*Client)(nil)
+
+// NewClient creates a Client sending the events to dsn.
+func NewClient(dsn string, opts ...Option) (*Client, error) {
+	parsed, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		dsn:        parsed,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Capture converts err into an Event and sends it. It returns the id of the event.
+func (c *Client) Capture(ctx context.Context, err error) (string, error) {
+	event := NewEvent(err)
+	if sendErr := c.Send(ctx, event); sendErr != nil {
+		return "", sendErr
+	}
+	return event.EventID, nil
+}
+
+// Report implements report.Reporter.
+func (c *Client) Report(ctx context.Context, e *report.Event) error {
+	event := NewEvent(e.Err)
+	event.Timestamp = e.Time.UTC()
+	event.Level = levelOf(e.Severity)
+	event.Fingerprint = []string{e.Fingerprint}
+	return c.Send(ctx, event)
+}
+
+// Send sends event. The environment, release and server name of c are set if the event has none. A rate limited event
+// fails with a retryable error, carrying the delay requested by the server.
+func (c *Client) Send(ctx context.Context, event *Event) error {
+	if event.Environment == "" {
+		event.Environment = c.environment
+	}
+	if event.Release == "" {
+		event.Release = c.release
+	}
+	if event.ServerName == "" {
+		event.ServerName = c.serverName
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errorwrap.Wrap(err, ErrorSend)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.dsn.StoreURL(), bytes.NewReader(body))
+	if err != nil {
+		return errorwrap.Wrap(err, ErrorSend)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", clientName)
+	req.Header.Set("X-Sentry-Auth", c.dsn.AuthHeader())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errorwrap.Wrap(err, ErrorSend)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	msg := "status " + strconv.Itoa(resp.StatusCode)
+	if reason := resp.Header.Get("X-Sentry-Error"); reason != "" {
+		msg += ": " + reason
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter := time.Duration(0)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return errorwrap.MarkRetryable(errorwrap.NewErrorWithMessage(msg, ErrorSend), retryAfter)
+	case resp.StatusCode >= 500:
+		return errorwrap.MarkRetryable(errorwrap.NewErrorWithMessage(msg, ErrorSend), 0)
+	}
+	return errorwrap.NewErrorWithMessage(msg, ErrorSend, ErrorRejected)
+}
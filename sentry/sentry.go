@@ -0,0 +1,294 @@
+// Package sentry exports errors to Sentry, or to any service accepting the Sentry event payload, without depending on
+// the Sentry SDK.
+//
+// Every level of an ErrorWrapper becomes an entry of the exception interface, with the frames of its own stack trace,
+// so the whole chain is visible in Sentry instead of a single flattened message:
+//
+//	client, err := sentry.NewClient(os.Getenv("SENTRY_DSN"), sentry.WithEnvironment("production"))
+//	...
+//	eventID, err := client.Capture(ctx, err)
+//
+// A Client is also a report.Reporter, so it can be registered into a report.Pipeline.
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxTagLength is the maximum length of a tag value accepted by Sentry.
+const maxTagLength = 200
+
+// Event is the Sentry event payload.
+type Event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Message     string                 `json:"message,omitempty"`
+	Fingerprint []string               `json:"fingerprint,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   *Exceptions            `json:"exception,omitempty"`
+}
+
+// Exceptions is the exception interface of an Event. Values are ordered from the root cause to the current level, as
+// expected by Sentry.
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+// Exception is a level of an ErrorWrapper.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace is the stack trace of an Exception. Frames are ordered from the outermost call, as expected by Sentry.
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a frame of a Stacktrace.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+// level is a level of the redacted JSON encoding of an ErrorWrapper.
+type level struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+	Context string        `json:"context"`
+	Details []interface{} `json:"details"`
+}
+
+// NewEvent converts err into an Event. The messages, context messages and fields are redacted as in the JSON encoding
+// of err. The fields of the whole chain become tags, the context messages and details become extras.
+func NewEvent(err error) *Event {
+	event := &Event{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC(),
+		Level:       levelOf(errorwrap.SeverityOf(err)),
+		Platform:    "go",
+		Fingerprint: []string{errorwrap.Fingerprint(err)},
+		Exception:   &Exceptions{},
+	}
+	if err == nil {
+		return event
+	}
+
+	var levels []level
+	if m, ok := err.(json.Marshaler); ok {
+		var encoded struct {
+			Levels []level `json:"levels"`
+		}
+		if b, jsonErr := m.MarshalJSON(); jsonErr == nil && json.Unmarshal(b, &encoded) == nil {
+			levels = encoded.Levels
+		}
+	}
+
+	var contexts []string
+	var details []interface{}
+	i := 0
+	for cur := err; cur != nil; cur, i = errorwrap.Unwrap(cur), i+1 {
+		exception := Exception{}
+		ew, ok := cur.(errorwrap.ErrorWrapper)
+		if !ok {
+			exception.Type, exception.Module = typeOf(cur)
+			exception.Value = cur.Error()
+			event.Exception.Values = append(event.Exception.Values, exception)
+			break
+		}
+
+		var messages []string
+		if i < len(levels) {
+			for _, e := range levels[i].Errors {
+				messages = append(messages, e.Message)
+			}
+			if levels[i].Context != "" {
+				contexts = append(contexts, levels[i].Context)
+			}
+			details = append(details, levels[i].Details...)
+		}
+		if curErrs := ew.CurrentError(); len(curErrs) > 0 {
+			exception.Type, exception.Module = typeOf(curErrs[0])
+		}
+		exception.Value = strings.Join(messages, "; ")
+		exception.Stacktrace = newStacktrace(ew.StackTrace())
+		event.Exception.Values = append(event.Exception.Values, exception)
+	}
+
+	values := event.Exception.Values
+	for l, r := 0, len(values)-1; l < r; l, r = l+1, r-1 {
+		values[l], values[r] = values[r], values[l]
+	}
+	if len(values) > 0 {
+		event.Message = values[len(values)-1].Value
+	}
+
+	if fields := errorwrap.FieldsOf(err); len(fields) > 0 {
+		event.Tags = make(map[string]string, len(fields))
+		for k, v := range fields {
+			tag := fmt.Sprint(v)
+			if len(tag) > maxTagLength {
+				cut := maxTagLength
+				for cut > 0 && !utf8.RuneStart(tag[cut]) {
+					cut--
+				}
+				tag = tag[:cut]
+			}
+			event.Tags[k] = tag
+		}
+	}
+	if category := errorwrap.CategoryOf(err); category != errorwrap.CategoryUnknown {
+		if event.Tags == nil {
+			event.Tags = map[string]string{}
+		}
+		event.Tags["category"] = string(category)
+	}
+	if len(contexts) > 0 || len(details) > 0 {
+		event.Extra = map[string]interface{}{}
+		if len(contexts) > 0 {
+			event.Extra["context"] = contexts
+		}
+		if len(details) > 0 {
+			event.Extra["details"] = details
+		}
+	}
+	return event
+}
+
+// typeOf returns the exception type of err: the code of an ErrorDefinition, the message of an ErrorDefinition without
+// code, or the Go type of a foreign error.
+func typeOf(err error) (string, string) {
+	if code := errorwrap.Code(err); code != "" {
+		return code, ""
+	}
+	if _, ok := err.(errorwrap.ErrorDefinition); ok {
+		return err.Error(), ""
+	}
+	t := fmt.Sprintf("%T", err)
+	if i := strings.LastIndex(t, "."); i > 0 {
+		return t[i+1:], strings.TrimLeft(t[:i], "*")
+	}
+	return t, ""
+}
+
+func newStacktrace(st errorwrap.StackTrace) *Stacktrace {
+	if len(st) == 0 {
+		return nil
+	}
+	frames := make([]Frame, 0, len(st))
+	for i := len(st) - 1; i >= 0; i-- {
+		pc := uintptr(st[i]) - 1
+		fn := runtime.FuncForPC(pc)
+		if fn == nil {
+			frames = append(frames, Frame{Function: "unknown"})
+			continue
+		}
+		file, line := fn.FileLine(pc)
+		module, function := splitFunction(fn.Name())
+		frames = append(frames, Frame{
+			Function: function,
+			Module:   module,
+			AbsPath:  file,
+			Filename: trimFilename(file, module),
+			Lineno:   line,
+			InApp:    isApp(module),
+		})
+	}
+	return &Stacktrace{Frames: frames}
+}
+
+// splitFunction splits a function name reported by runtime.Func.Name into the package path and the function name. The
+// dots of the last element of the package path are escaped in the function name, e.g. "gopkg.in/yaml%2ev3.Marshal".
+func splitFunction(name string) (string, string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return strings.ReplaceAll(name[:slash+1+dot], "%2e", "."), name[slash+1+dot+1:]
+}
+
+// trimFilename returns the path of file relative to the parent of the package directory, e.g. "errorwrap/stack.go".
+func trimFilename(file, module string) string {
+	pkg := module
+	if i := strings.LastIndex(module, "/"); i >= 0 {
+		pkg = module[i+1:]
+	}
+	if i := strings.LastIndex(file, "/"+pkg+"/"); i >= 0 {
+		return file[i+1:]
+	}
+	return file
+}
+
+var (
+	appMu       sync.RWMutex
+	appPrefixes = mainModule()
+)
+
+// SetAppPrefixes sets the package path prefixes of the frames marked as in-app, i.e. the frames of the application
+// instead of its dependencies or the standard library. By default, it is the path of the main module.
+func SetAppPrefixes(prefixes ...string) {
+	appMu.Lock()
+	defer appMu.Unlock()
+	appPrefixes = prefixes
+}
+
+// mainModule returns the path of the main module, if the binary was built with module support.
+func mainModule() []string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		return []string{info.Main.Path}
+	}
+	return nil
+}
+
+// isApp checks whether module is the main package, e.g. of a program built without module, or a package under one of
+// the app prefixes.
+func isApp(module string) bool {
+	if module == "main" {
+		return true
+	}
+	appMu.RLock()
+	defer appMu.RUnlock()
+	for _, prefix := range appPrefixes {
+		if module == prefix || strings.HasPrefix(module, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func levelOf(severity errorwrap.Severity) string {
+	if severity == errorwrap.SeverityUnknown {
+		return errorwrap.SeverityError.String()
+	}
+	return severity.String()
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
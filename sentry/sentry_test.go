@@ -0,0 +1,138 @@
+package sentry
+
+import (
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var (
+	ErrorInfraDatabase = errorwrap.New("error database", errorwrap.WithCode("DATABASE"),
+		errorwrap.WithCategory(errorwrap.CategoryUnavailable))
+	ErrorDomainUser = errorwrap.New("error domain user", errorwrap.WithSeverity(errorwrap.SeverityWarning))
+	ErrorSecret     = errorwrap.New("error secret", errorwrap.Sensitive())
+)
+
+type quota struct {
+	Limit int `json:"limit"`
+}
+
+func infraLayer() error {
+	err := errorwrap.Wrap(errors.New("connection refused"), ErrorInfraDatabase)
+	return errorwrap.AppendFields(err, errorwrap.Fields{"table": "users", "query": strings.Repeat("x", 300),
+		"name": strings.Repeat("x", maxTagLength-1) + "é"})
+}
+
+func domainLayer() error {
+	err := errorwrap.WrapWithMessage(infraLayer(), "find user 42", ErrorDomainUser, ErrorSecret)
+	return errorwrap.AppendDetails(err, quota{Limit: 10})
+}
+
+func TestNewEvent(t *testing.T) {
+	event := NewEvent(domainLayer())
+
+	assert.Len(t, event.EventID, 32)
+	assert.Equal(t, "warning", event.Level)
+	assert.Equal(t, "go", event.Platform)
+	assert.Equal(t, []string{errorwrap.Fingerprint(domainLayer())}, event.Fingerprint)
+	assert.Equal(t, "error domain user; [REDACTED]", event.Message)
+
+	if assert.Len(t, event.Exception.Values, 3) {
+		root, infra, domain := event.Exception.Values[0], event.Exception.Values[1], event.Exception.Values[2]
+
+		assert.Equal(t, "errorString", root.Type)
+		assert.Equal(t, "errors", root.Module)
+		assert.Equal(t, "connection refused", root.Value)
+
+		assert.Equal(t, "DATABASE", infra.Type)
+		assert.Equal(t, "error database", infra.Value)
+
+		assert.Equal(t, "error domain user", domain.Type)
+		if assert.NotNil(t, domain.Stacktrace) {
+			frames := domain.Stacktrace.Frames
+			last := frames[len(frames)-1]
+			assert.Equal(t, "domainLayer", last.Function)
+			assert.Equal(t, "github.com/anantadwi13/errorwrap/sentry", last.Module)
+			assert.Equal(t, "sentry/sentry_test.go", last.Filename)
+			assert.True(t, strings.HasSuffix(last.AbsPath, "/sentry/sentry_test.go"))
+			assert.NotZero(t, last.Lineno)
+			assert.True(t, last.InApp)
+			assert.False(t, frames[0].InApp)
+		}
+		if assert.NotNil(t, infra.Stacktrace) {
+			frames := infra.Stacktrace.Frames
+			assert.Equal(t, "infraLayer", frames[len(frames)-1].Function)
+		}
+	}
+
+	assert.Equal(t, "users", event.Tags["table"])
+	assert.Len(t, event.Tags["query"], maxTagLength)
+	assert.Equal(t, strings.Repeat("x", maxTagLength-1), event.Tags["name"])
+	assert.Equal(t, "unavailable", event.Tags["category"])
+	assert.Equal(t, []string{"find user 42"}, event.Extra["context"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"limit": float64(10)}}, event.Extra["details"])
+}
+
+func TestNewEvent_Foreign(t *testing.T) {
+	event := NewEvent(errors.New("foreign"))
+	assert.Equal(t, "error", event.Level)
+	assert.Equal(t, "foreign", event.Message)
+	assert.Equal(t, []Exception{{Type: "errorString", Module: "errors", Value: "foreign"}}, event.Exception.Values)
+	assert.Nil(t, event.Tags)
+	assert.Nil(t, event.Extra)
+}
+
+func TestSplitFunction(t *testing.T) {
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantModule   string
+		wantFunction string
+	}{
+		{name: "runtime", args: args{name: "runtime.main"}, wantModule: "runtime", wantFunction: "main"},
+		{name: "method", args: args{name: "github.com/a/b.(*T).m"}, wantModule: "github.com/a/b", wantFunction: "(*T).m"},
+		{name: "closure", args: args{name: "github.com/a/b.c.func1"}, wantModule: "github.com/a/b", wantFunction: "c.func1"},
+		{name: "dotted path", args: args{name: "gopkg.in/yaml%2ev3.Marshal"}, wantModule: "gopkg.in/yaml.v3", wantFunction: "Marshal"},
+		{name: "no package", args: args{name: "main"}, wantModule: "", wantFunction: "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, function := splitFunction(tt.args.name)
+			assert.Equal(t, tt.wantModule, module)
+			assert.Equal(t, tt.wantFunction, function)
+		})
+	}
+}
+
+func TestIsApp(t *testing.T) {
+	type args struct {
+		module string
+	}
+	tests := []struct {
+		name     string
+		prefixes []string
+		args     args
+		want     bool
+	}{
+		{name: "main module", args: args{module: "github.com/anantadwi13/errorwrap/sentry"}, want: true},
+		{name: "main package without module", args: args{module: "main"}, want: true},
+		{name: "standard library", args: args{module: "net/http"}, want: false},
+		{name: "dependency", args: args{module: "github.com/lib/pq"}, want: false},
+		{name: "prefix of another path", prefixes: []string{"github.com/acme/app"}, args: args{module: "github.com/acme/application"}, want: false},
+		{name: "configured prefix", prefixes: []string{"github.com/acme/app"}, args: args{module: "github.com/acme/app/internal/user"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prefixes != nil {
+				SetAppPrefixes(tt.prefixes...)
+				defer SetAppPrefixes(mainModule()...)
+			}
+			assert.Equal(t, tt.want, isApp(tt.args.module))
+		})
+	}
+}
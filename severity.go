@@ -0,0 +1,51 @@
+package errorwrap
+
+// Severity tells how serious a failure is. It is used to decide which errors are reported, e.g. to an error tracker.
+type Severity int8
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityFatal:
+		return "fatal"
+	}
+	return "unknown"
+}
+
+// WithSeverity sets the Severity of an ErrorDefinition.
+func WithSeverity(severity Severity) DefinitionOption {
+	return func(d *errorDefinition) {
+		d.severity = severity
+	}
+}
+
+// SeverityOf returns the Severity of err. It will find recursively from current level to the root of ErrorWrapper
+// stack and returns the Severity of the first ErrorDefinition that has one. If there is none, SeverityOf returns
+// SeverityUnknown.
+func SeverityOf(err error) Severity {
+	severity := SeverityUnknown
+	walkErrors(err, func(err error) bool {
+		if def := definitionOf(err); def != nil && def.severity != SeverityUnknown {
+			severity = def.severity
+			return true
+		}
+		return false
+	})
+	return severity
+}
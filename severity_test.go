@@ -0,0 +1,40 @@
+package errorwrap
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorSeverityWarning = New("error warning", WithSeverity(SeverityWarning))
+	ErrorSeverityFatal   = New("error fatal", WithSeverity(SeverityFatal))
+)
+
+func TestSeverityOf(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want Severity
+	}{
+		{name: "nil error", args: args{err: nil}, want: SeverityUnknown},
+		{name: "without severity", args: args{err: appLayer()}, want: SeverityUnknown},
+		{name: "definition", args: args{err: ErrorSeverityWarning}, want: SeverityWarning},
+		{name: "root level", args: args{err: Wrap(NewError(ErrorSeverityFatal), ErrorDomain)}, want: SeverityFatal},
+		{name: "upper level first", args: args{err: Wrap(NewError(ErrorSeverityFatal), ErrorSeverityWarning)}, want: SeverityWarning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SeverityOf(tt.args.err))
+		})
+	}
+}
+
+func TestSeverity_String(t *testing.T) {
+	assert.Equal(t, "unknown", SeverityUnknown.String())
+	assert.Equal(t, "warning", SeverityWarning.String())
+	assert.Equal(t, "fatal", SeverityFatal.String())
+	assert.Equal(t, "unknown", Severity(42).String())
+}
@@ -38,6 +38,9 @@ func (st *stack) StackTrace() StackTrace {
 }
 
 func callStack() *stack {
+	if !CaptureEnabled() {
+		return &stack{}
+	}
 	pcs := make([]uintptr, 32)
 	n := runtime.Callers(3, pcs)
 	var st stack = pcs[0:n]
@@ -0,0 +1,94 @@
+package errorwrap
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{([A-Za-z0-9_.\-]+)\}`)
+
+// Template is an ErrorDefinition with a parameterized message, e.g. "user {id} not found". An error instantiated from
+// a Template still matches the Template under Is.
+type Template interface {
+	ErrorDefinition
+	// With instantiates the template. The placeholders of the message and the public message are replaced by fields,
+	// and fields are kept as the fields of the returned error.
+	With(fields Fields) error
+}
+
+type templateDefinition struct {
+	*errorDefinition
+}
+
+func (t *templateDefinition) With(fields Fields) error {
+	copied := make(Fields, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	return &templateError{
+		template: t,
+		fields:   copied,
+	}
+}
+
+type templateError struct {
+	template *templateDefinition
+	fields   Fields
+}
+
+func (e *templateError) Error() string {
+	return e.message(true)
+}
+
+func (e *templateError) message(redact bool) string {
+	if redact && e.template.sensitive {
+		return redactedText
+	}
+	return renderTemplate(e.template.msg, e.fields, redact)
+}
+
+func (e *templateError) PublicMessage() string {
+	return renderTemplate(e.template.publicMsg, e.fields, true)
+}
+
+func (e *templateError) Fields() Fields {
+	return e.fields
+}
+
+func (e *templateError) Is(target error) bool {
+	return target == e.template
+}
+
+func (e *templateError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		fallthrough
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// NewTemplate creates a Template. Placeholders are written as {key} and are replaced by the value of key when the
+// template is instantiated using Template.With. Unknown placeholders are kept as is.
+func NewTemplate(message string, opts ...DefinitionOption) Template {
+	return &templateDefinition{
+		errorDefinition: New(message, opts...).(*errorDefinition),
+	}
+}
+
+func renderTemplate(message string, fields Fields, redact bool) string {
+	if message == "" || len(fields) == 0 {
+		return message
+	}
+	return templatePlaceholder.ReplaceAllStringFunc(message, func(placeholder string) string {
+		key := placeholder[1 : len(placeholder)-1]
+		value, ok := fields[key]
+		if !ok {
+			return placeholder
+		}
+		return fmt.Sprint(fieldValue(key, value, redact))
+	})
+}
@@ -0,0 +1,86 @@
+package errorwrap
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ErrorTemplateUserNotFound = NewTemplate("user {id} not found", WithPublicMessage("user {id} does not exist"))
+	ErrorTemplateLogin        = NewTemplate("login failed for {email}")
+)
+
+func TestNewTemplate(t *testing.T) {
+	type args struct {
+		template Template
+		fields   Fields
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantMessage   string
+		wantPublicMsg string
+	}{
+		{
+			name:          "all placeholders replaced",
+			args:          args{template: ErrorTemplateUserNotFound, fields: Fields{"id": 42}},
+			wantMessage:   "user 42 not found",
+			wantPublicMsg: "user 42 does not exist",
+		},
+		{
+			name:          "missing placeholder kept",
+			args:          args{template: ErrorTemplateUserNotFound, fields: nil},
+			wantMessage:   "user {id} not found",
+			wantPublicMsg: "user {id} does not exist",
+		},
+		{
+			name:          "without public message",
+			args:          args{template: ErrorTemplateLogin, fields: Fields{"email": "john@example.com"}},
+			wantMessage:   "login failed for john@example.com",
+			wantPublicMsg: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.args.template.With(tt.args.fields)
+			assert.Equal(t, tt.wantMessage, got.Error())
+			assert.Equal(t, tt.wantPublicMsg, got.(publicMessager).PublicMessage())
+			assert.True(t, Is(got, tt.args.template))
+
+			wrapped := Wrap(NewError(got), ErrorDomain)
+			assert.True(t, Is(wrapped, tt.args.template))
+			assert.True(t, IsExact(Wrapper(wrapped, tt.args.template), tt.args.template))
+			assert.Equal(t, tt.args.template, Wrapper(wrapped, tt.args.template).CurrentError()[0].(*templateError).template)
+		})
+	}
+}
+
+func TestDefinitionMessage(t *testing.T) {
+	assert.Equal(t, "user {id} not found", DefinitionMessage(ErrorTemplateUserNotFound.With(Fields{"id": 42})))
+	assert.Equal(t, "user {id} not found", DefinitionMessage(ErrorTemplateUserNotFound))
+	assert.Equal(t, "secret", DefinitionMessage(New("secret", Sensitive())))
+	assert.Empty(t, DefinitionMessage(NewError(ErrorTemplateUserNotFound.With(Fields{"id": 42}))))
+	assert.Empty(t, DefinitionMessage(fmt.Errorf("foreign")))
+}
+
+func TestTemplateIsNotOtherTemplate(t *testing.T) {
+	err := NewError(ErrorTemplateUserNotFound.With(Fields{"id": 1}))
+	assert.False(t, Is(err, ErrorTemplateLogin))
+	assert.False(t, Is(err, ErrorCommonNotFound))
+}
+
+func TestTemplateRedaction(t *testing.T) {
+	RedactFields("email")
+	t.Cleanup(func() {
+		redactionMu.Lock()
+		delete(redactionFields, "email")
+		redactionMu.Unlock()
+	})
+
+	err := NewError(ErrorTemplateLogin.With(Fields{"email": "john@example.com"}))
+	assert.Equal(t, " -  login failed for [REDACTED]", err.Error())
+	assert.NotContains(t, fmt.Sprintf("%+v", err), "john@example.com")
+	assert.Equal(t, Fields{"email": "[REDACTED]"}, FieldsOf(err))
+	assert.Contains(t, fmt.Sprintf("%+v", Unsafe(err)), "login failed for john@example.com")
+}
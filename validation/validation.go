@@ -0,0 +1,187 @@
+// Package validation aggregates field-level validation problems into an errorwrap.ErrorWrapper.
+//
+// A validation error is an ErrorWrapper whose CurrentError contains ErrValidation followed by one Violation per
+// problem, so errorwrap.Is(err, ErrValidation) and errorwrap.Is(err, <violation definition>) both match.
+package validation
+
+import (
+	"github.com/anantadwi13/errorwrap"
+	"sort"
+	"strings"
+)
+
+// GenericReason is returned by Violation.Reason when there is neither a public message nor a rule.
+var GenericReason = "invalid value"
+
+var (
+	// ErrValidation is placed in every validation error.
+	ErrValidation = errorwrap.New("validation failed", errorwrap.WithCode("VALIDATION_FAILED"),
+		errorwrap.WithCategory(errorwrap.CategoryInvalidArgument), errorwrap.WithPublicMessage("request validation failed"))
+)
+
+// publicMessager is implemented by the errorwrap definitions and templates.
+type publicMessager interface {
+	PublicMessage() string
+}
+
+// Violation is a validation problem of a single field.
+type Violation struct {
+	// Field is the path of the field, e.g. "address.street" or "items[0].quantity".
+	Field string
+	// Rule is the name of the violated rule, e.g. "required" or "max".
+	Rule string
+	// Err describes the problem. It is recommended to pass ErrorDefinition.
+	Err error
+}
+
+// NewViolation creates a Violation.
+func NewViolation(field, rule string, err error) *Violation {
+	return &Violation{
+		Field: field,
+		Rule:  rule,
+		Err:   err,
+	}
+}
+
+func (v *Violation) Error() string {
+	msg := ""
+	if v.Err != nil {
+		msg = v.Err.Error()
+	}
+	if v.Field == "" {
+		return msg
+	}
+	return v.Field + ": " + msg
+}
+
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// Reason returns the user-safe description of the violation. It is the public message of Err if Err has one,
+// otherwise Rule. The message of Err is never used since it may contain internal details. If Rule is empty as well,
+// GenericReason is returned.
+func (v *Violation) Reason() string {
+	if def, ok := v.Err.(publicMessager); ok && def.PublicMessage() != "" {
+		return def.PublicMessage()
+	}
+	if v.Rule != "" {
+		return v.Rule
+	}
+	return GenericReason
+}
+
+// New creates a validation error containing violations. If there is no violation then New returns nil.
+func New(violations ...*Violation) error {
+	errs := violationErrors(violations)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errorwrap.NewError(append([]error{ErrValidation}, errs...)...)
+}
+
+// Append appends violations into err using errorwrap.AppendInto semantics. ErrValidation is added into the current
+// level of err if it is not there yet. If err is nil then Append behaves like New.
+func Append(err error, violations ...*Violation) error {
+	errs := violationErrors(violations)
+	if err == nil {
+		if len(errs) == 0 {
+			return nil
+		}
+		return errorwrap.NewError(append([]error{ErrValidation}, errs...)...)
+	}
+	if !errorwrap.IsExact(err, ErrValidation) {
+		errs = append([]error{ErrValidation}, errs...)
+	}
+	return errorwrap.AppendInto(err, errs...)
+}
+
+// Merge appends the violations of nested, the validation error of a nested struct or a slice element, into err. The
+// field paths of the nested violations are prefixed with prefix, e.g. prefix "address" turns "street" into
+// "address.street" and prefix "items" turns "[0].quantity" into "items[0].quantity".
+func Merge(err error, prefix string, nested error) error {
+	var violations []*Violation
+	for _, v := range Violations(nested) {
+		violations = append(violations, NewViolation(JoinField(prefix, v.Field), v.Rule, v.Err))
+	}
+	if len(violations) == 0 {
+		return err
+	}
+	return Append(err, violations...)
+}
+
+// JoinField joins field path segments. Segments starting with "[" are appended without a separator.
+func JoinField(segments ...string) string {
+	path := ""
+	for _, segment := range segments {
+		switch {
+		case segment == "":
+		case path == "", strings.HasPrefix(segment, "["):
+			path += segment
+		default:
+			path += "." + segment
+		}
+	}
+	return path
+}
+
+// Violations returns the violations of the first validation level of err.
+func Violations(err error) []*Violation {
+	wrapper := errorwrap.Wrapper(err, ErrValidation)
+	if wrapper == nil {
+		return nil
+	}
+	var violations []*Violation
+	for _, curErr := range wrapper.CurrentError() {
+		if v, ok := curErr.(*Violation); ok {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// ToMap returns the reasons of the violations of err grouped by field path.
+func ToMap(err error) map[string][]string {
+	violations := Violations(err)
+	if len(violations) == 0 {
+		return nil
+	}
+	m := make(map[string][]string, len(violations))
+	for _, v := range violations {
+		m[v.Field] = append(m[v.Field], v.Reason())
+	}
+	return m
+}
+
+// InvalidParam is a member of the "invalid-params" extension of a problem+json (RFC 7807) document.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// InvalidParams returns the violations of err in the problem+json "invalid-params" form, sorted by field path.
+func InvalidParams(err error) []InvalidParam {
+	violations := Violations(err)
+	if len(violations) == 0 {
+		return nil
+	}
+	params := make([]InvalidParam, 0, len(violations))
+	for _, v := range violations {
+		params = append(params, InvalidParam{Name: v.Field, Reason: v.Reason()})
+	}
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
+
+func violationErrors(violations []*Violation) []error {
+	var errs []error
+	for _, v := range violations {
+		if v == nil {
+			continue
+		}
+		errs = append(errs, v)
+	}
+	return errs
+}
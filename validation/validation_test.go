@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+var (
+	ErrorRequired = errorwrap.New("field is required", errorwrap.WithPublicMessage("must not be empty"))
+	ErrorTooLong  = errorwrap.New("field is too long")
+	ErrorInvalid  = errorwrap.New("field is invalid")
+)
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New())
+	assert.Nil(t, New(nil))
+
+	err := New(NewViolation("name", "required", ErrorRequired), NewViolation("bio", "max", ErrorTooLong))
+	assert.True(t, errorwrap.Is(err, ErrValidation))
+	assert.True(t, errorwrap.Is(err, ErrorRequired))
+	assert.True(t, errorwrap.Is(err, ErrorTooLong))
+	assert.False(t, errorwrap.Is(err, ErrorInvalid))
+	assert.Equal(t, " -  validation failed\n    name: field is required\n    bio: field is too long", err.Error())
+	assert.Equal(t, "request validation failed", errorwrap.PublicMessage(err))
+}
+
+func TestViolationReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		violation *Violation
+		want      string
+	}{
+		{
+			name:      "public message",
+			violation: NewViolation("name", "required", ErrorRequired),
+			want:      "must not be empty",
+		},
+		{
+			name:      "definition without public message",
+			violation: NewViolation("age", "min", ErrorInvalid),
+			want:      "min",
+		},
+		{
+			name:      "foreign error",
+			violation: NewViolation("email", "email", fmt.Errorf("lookup mx of secret.internal: %w", io.EOF)),
+			want:      "email",
+		},
+		{
+			name:      "no rule",
+			violation: NewViolation("email", "", errors.New("dial tcp 10.0.0.1:25: connection refused")),
+			want:      GenericReason,
+		},
+		{
+			name:      "no error",
+			violation: NewViolation("email", "email", nil),
+			want:      "email",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.violation.Reason())
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	type args struct {
+		err        error
+		violations []*Violation
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantNil bool
+		want    map[string][]string
+	}{
+		{
+			name:    "expect nil",
+			args:    args{err: nil, violations: nil},
+			wantNil: true,
+		},
+		{
+			name: "nil error",
+			args: args{err: nil, violations: []*Violation{NewViolation("name", "required", ErrorRequired)}},
+			want: map[string][]string{"name": {"must not be empty"}},
+		},
+		{
+			name: "validation error",
+			args: args{
+				err:        New(NewViolation("name", "required", ErrorRequired)),
+				violations: []*Violation{NewViolation("name", "max", ErrorTooLong)},
+			},
+			want: map[string][]string{"name": {"must not be empty", "max"}},
+		},
+		{
+			name: "other error wrapper",
+			args: args{
+				err:        errorwrap.NewError(ErrorInvalid),
+				violations: []*Violation{NewViolation("email", "email", ErrorInvalid)},
+			},
+			want: map[string][]string{"email": {"email"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Append(tt.args.err, tt.args.violations...)
+			if tt.wantNil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.True(t, errorwrap.IsExact(got, ErrValidation))
+			assert.Equal(t, tt.want, ToMap(got))
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	address := New(NewViolation("street", "required", ErrorRequired), NewViolation("zip", "format", ErrorInvalid))
+	item := New(NewViolation("quantity", "min", ErrorInvalid))
+	items := Merge(nil, "[1]", item)
+
+	err := New(NewViolation("name", "required", ErrorRequired))
+	err = Merge(err, "address", address)
+	err = Merge(err, "items", items)
+	err = Merge(err, "empty", nil)
+
+	assert.Equal(t, map[string][]string{
+		"name":              {"must not be empty"},
+		"address.street":    {"must not be empty"},
+		"address.zip":       {"format"},
+		"items[1].quantity": {"min"},
+	}, ToMap(err))
+	assert.Nil(t, Merge(nil, "address", nil))
+}
+
+func TestJoinField(t *testing.T) {
+	assert.Equal(t, "", JoinField())
+	assert.Equal(t, "address.street", JoinField("address", "street"))
+	assert.Equal(t, "items[0].name", JoinField("items", "[0]", "", "name"))
+	assert.Equal(t, "[0]", JoinField("", "[0]"))
+}
+
+func TestInvalidParams(t *testing.T) {
+	err := errorwrap.Wrap(New(
+		NewViolation("name", "required", ErrorRequired),
+		NewViolation("age", "min", ErrorInvalid),
+	), errorwrap.New("error usecase layer"))
+
+	params := InvalidParams(err)
+	assert.Equal(t, []InvalidParam{
+		{Name: "age", Reason: "min"},
+		{Name: "name", Reason: "must not be empty"},
+	}, params)
+
+	data, e := json.Marshal(params)
+	assert.NoError(t, e)
+	assert.JSONEq(t, `[{"name":"age","reason":"min"},{"name":"name","reason":"must not be empty"}]`, string(data))
+
+	assert.Nil(t, InvalidParams(errorwrap.NewError(ErrorInvalid)))
+	assert.Nil(t, ToMap(nil))
+}
@@ -0,0 +1,206 @@
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+)
+
+// Decode decodes data encoded by Encode. Note that it returns two errors: decoded is the error carried by data, and err
+// reports why data could not be decoded, in which case decoded is nil.
+//
+//	decoded, err := wire.Decode(data)
+//	if err != nil {
+//		// data is invalid
+//	}
+//
+// decoded is an ErrorWrapper with the same levels as the encoded error. The codes are resolved into the definitions
+// registered using errorwrap.RegisterDefinition, unknown codes become new definitions whose message is the code. The
+// instances of a registered Template are instantiated again with their fields, whose values are strings. The
+// decoded levels capture a new stack trace, the encoded frames are added into their details as RemoteFrames.
+//
+// Decode never panics on arbitrary data. It returns ErrorMalformed if data is not a valid encoding,
+// ErrorUnsupportedVersion if data was encoded by a newer version, and ErrorTooLarge if data exceeds the maximum size,
+// the maximum number of levels or the maximum length of a string, i.e. anything Encode would have truncated.
+func Decode(data []byte, opts ...Option) (decoded error, err error) {
+	o := newOptions(opts)
+	if len(data) > o.maxSize {
+		return nil, errorwrap.NewErrorWithMessage(
+			fmt.Sprintf("%d bytes exceed the maximum size of %d bytes", len(data), o.maxSize), ErrorTooLarge)
+	}
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, errorwrap.NewErrorWithMessage("missing header", ErrorMalformed)
+	}
+	if version := data[len(magic)]; version != Version {
+		return nil, errorwrap.NewErrorWithMessage(fmt.Sprintf("version %d", version), ErrorUnsupportedVersion)
+	}
+	flags := data[len(magic)+1]
+
+	r := &reader{data: data, off: headerSize, maxString: o.maxString}
+	count := r.limitedCount(o.maxLevels, "levels")
+	if count == 0 && r.err == nil {
+		r.fail("no level")
+	}
+	var levels []level
+	for i := 0; i < count && r.err == nil; i++ {
+		levels = append(levels, r.level(flags&flagFrames != 0))
+	}
+	if r.err == nil && r.off != len(r.data) {
+		r.fail("trailing bytes")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		lv := levels[i]
+		entries := make([]error, 0, len(lv.entries))
+		for _, e := range lv.entries {
+			entries = append(entries, decodeEntry(e))
+		}
+		if decoded == nil {
+			decoded = errorwrap.NewErrorWithMessage(lv.context, entries...)
+		} else {
+			decoded = errorwrap.WrapWithMessage(decoded, lv.context, entries...)
+		}
+		if len(lv.fields) > 0 {
+			fields := errorwrap.Fields{}
+			for _, f := range lv.fields {
+				fields[f[0]] = f[1]
+			}
+			decoded = errorwrap.AppendFields(decoded, fields)
+		}
+		if len(lv.frames) > 0 {
+			decoded = errorwrap.AppendDetails(decoded, RemoteFrames(lv.frames))
+		}
+	}
+	return decoded, nil
+}
+
+func decodeEntry(e entry) error {
+	if e.tag == tagMessage {
+		return errors.New(e.value)
+	}
+	def, ok := errorwrap.DefinitionByCode(e.value)
+	if !ok {
+		return errorwrap.New(e.value, errorwrap.WithCode(e.value))
+	}
+	if tmpl, ok := def.(errorwrap.Template); ok && e.tag == tagTemplate {
+		fields := make(errorwrap.Fields, len(e.fields))
+		for _, f := range e.fields {
+			fields[f[0]] = f[1]
+		}
+		return tmpl.With(fields)
+	}
+	return def
+}
+
+// reader reads data from off. Once a read fails, err is set and the following reads return zero values.
+type reader struct {
+	data      []byte
+	off       int
+	maxString int
+	err       error
+}
+
+func (r *reader) fail(msg string) {
+	r.failWith(ErrorMalformed, msg)
+}
+
+func (r *reader) failWith(def error, msg string) {
+	if r.err == nil {
+		r.err = errorwrap.NewErrorWithMessage(fmt.Sprintf("%s at offset %d", msg, r.off), def)
+	}
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.off >= len(r.data) {
+		r.fail("unexpected end")
+		return 0
+	}
+	b := r.data[r.off]
+	r.off++
+	return b
+}
+
+// count reads a number of items. Since every item takes at least one byte, a count larger than the remaining bytes
+// is rejected before anything is allocated for it.
+func (r *reader) count() int {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.off:])
+	if n <= 0 {
+		r.fail("invalid varint")
+		return 0
+	}
+	r.off += n
+	if v > uint64(len(r.data)-r.off) {
+		r.fail("count out of range")
+		return 0
+	}
+	return int(v)
+}
+
+// limitedCount reads a number of items that must not exceed max.
+func (r *reader) limitedCount(max int, items string) int {
+	n := r.count()
+	if n > max {
+		r.failWith(ErrorTooLarge, fmt.Sprintf("%d %s exceed the maximum of %d", n, items, max))
+		return 0
+	}
+	return n
+}
+
+func (r *reader) string() string {
+	n := r.limitedCount(r.maxString, "bytes of string")
+	if r.err != nil {
+		return ""
+	}
+	s := string(r.data[r.off : r.off+n])
+	r.off += n
+	return s
+}
+
+func (r *reader) fields() [][2]string {
+	var fields [][2]string
+	n := r.limitedCount(maxItems, "fields")
+	for i := 0; i < n && r.err == nil; i++ {
+		fields = append(fields, [2]string{r.string(), r.string()})
+	}
+	return fields
+}
+
+func (r *reader) level(frames bool) level {
+	var lv level
+	n := r.limitedCount(maxItems, "errors")
+	if n == 0 && r.err == nil {
+		r.fail("level without error")
+	}
+	for i := 0; i < n && r.err == nil; i++ {
+		tag := r.byte()
+		if r.err == nil && tag != tagCode && tag != tagMessage && tag != tagTemplate {
+			r.fail(fmt.Sprintf("unknown tag %d", tag))
+		}
+		e := entry{tag: tag, value: r.string()}
+		if tag == tagTemplate {
+			e.fields = r.fields()
+		}
+		lv.entries = append(lv.entries, e)
+	}
+
+	lv.context = r.string()
+	lv.fields = r.fields()
+
+	if frames {
+		n = r.count()
+		for i := 0; i < n && r.err == nil; i++ {
+			lv.frames = append(lv.frames, r.string())
+		}
+	}
+	return lv
+}
@@ -0,0 +1,47 @@
+package wire
+
+import (
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"reflect"
+	"testing"
+)
+
+func FuzzDecode(f *testing.F) {
+	for _, err := range []error{
+		errorwrap.NewError(ErrorUserNotFound),
+		errorwrap.WrapWithMessage(errorwrap.NewError(errors.New("standard error")), "find user", ErrorDomain),
+		errorwrap.AppendFields(deepError(3), errorwrap.Fields{"user_id": 42}),
+		errorwrap.NewError(ErrorUserMissing.With(errorwrap.Fields{"id": 42, "region": "eu"})),
+	} {
+		data, encodeErr := Encode(err, WithFrames(4))
+		if encodeErr != nil {
+			f.Fatal(encodeErr)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte("EW\x01\x00"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := Decode(data)
+		if err != nil {
+			return
+		}
+		// Decode enforces the limits of Encode, so a decoded error encodes again within the same limits, and decodes
+		// into the same levels unless the redaction lengthened its strings.
+		reencoded, err := Encode(decoded)
+		if err != nil {
+			t.Fatalf("encode decoded error: %v", err)
+		}
+		redecoded, err := Decode(reencoded)
+		if err != nil {
+			t.Fatalf("decode re-encoded error: %v", err)
+		}
+		if Truncated(reencoded) {
+			return
+		}
+		if !reflect.DeepEqual(levelMessages(redecoded), levelMessages(decoded)) {
+			t.Fatalf("levels mismatch: %q != %q", levelMessages(redecoded), levelMessages(decoded))
+		}
+	})
+}
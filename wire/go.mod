@@ -0,0 +1,17 @@
+module github.com/anantadwi13/errorwrap/wire
+
+go 1.18
+
+require (
+	github.com/anantadwi13/errorwrap v0.0.0
+	github.com/stretchr/testify v1.7.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/anantadwi13/errorwrap => ../
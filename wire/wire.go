@@ -0,0 +1,371 @@
+// Package wire encodes errorwrap errors into a compact, versioned binary format meant for high-volume transports,
+// e.g. a message bus, and decodes them back.
+//
+// Every level of an ErrorWrapper is encoded, from the current level to the root, with the errors of the level, its
+// context message and its fields. Definitions registered using errorwrap.RegisterDefinition are encoded by their code
+// and resolved back by the decoding process. An error instantiated from a registered Template is encoded by the code
+// and its fields, and instantiated again using Template.With. Other errors are encoded by their message. The frames of the stack traces
+// are only encoded if WithFrames is given, as symbol strings, since program counters are meaningless in another
+// process. Like the JSON encoding, the context messages and the fields are redacted.
+//
+//	data, err := wire.Encode(err, wire.WithFrames(8))
+//	...
+//	decoded, err := wire.Decode(data)
+//	errorwrap.Is(decoded, ErrorUserNotFound) // true
+//
+// The encoded size is bounded by WithMaxSize. When an error does not fit, the frames are dropped first, then the
+// levels between the upper levels and the root, then the strings and the number of errors and fields of each level are
+// shortened. Truncated reports whether an encoded error was truncated.
+//
+// The format starts with the magic bytes "EW", the version and a flags byte. Then comes the number of levels followed
+// by the levels, each one being the errors of the level, the context message, the fields, and the frames if the frames
+// flag is set. Each error is a tag followed by a code, a message, or a code and fields. Counts and string lengths are
+// encoded as unsigned varints.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/anantadwi13/errorwrap"
+	"sort"
+	"unicode/utf8"
+)
+
+// Version is the version of the encoding produced by Encode.
+const Version = 1
+
+// Default limits of Encode and Decode.
+const (
+	DefaultMaxSize   = 8 << 10
+	DefaultMaxLevels = 32
+	DefaultMaxString = 512
+)
+
+const (
+	magic      = "EW"
+	headerSize = len(magic) + 2
+
+	flagFrames    byte = 1 << 0
+	flagTruncated byte = 1 << 1
+
+	tagCode     byte = 1
+	tagMessage  byte = 2
+	tagTemplate byte = 3
+
+	// maxItems is the maximum number of errors and fields of a level.
+	maxItems = 64
+	// minString is the length below which the strings are not shortened to meet the size limit.
+	minString = 16
+)
+
+var (
+	ErrorMalformed = errorwrap.New("malformed wire error",
+		errorwrap.WithCategory(errorwrap.CategoryInvalidArgument))
+	ErrorUnsupportedVersion = errorwrap.New("unsupported wire error version",
+		errorwrap.WithCategory(errorwrap.CategoryInvalidArgument))
+	ErrorTooLarge = errorwrap.New("wire error too large", errorwrap.WithCategory(errorwrap.CategoryInvalidArgument))
+)
+
+// RemoteFrames are the frames of a decoded level, formatted like errorwrap.Frame.MarshalText. They are added into the
+// details of the level and can be retrieved with errorwrap.Detail.
+type RemoteFrames []string
+
+// Option configures Encode and Decode.
+type Option func(o *options)
+
+type options struct {
+	maxSize   int
+	maxLevels int
+	maxString int
+	frames    int
+}
+
+// WithFrames encodes up to max frames of the stack trace of each level.
+func WithFrames(max int) Option {
+	return func(o *options) {
+		o.frames = max
+	}
+}
+
+// WithMaxSize sets the maximum size in bytes of an encoded error. Encode truncates the errors that are larger, and
+// Decode rejects them. Defaults to DefaultMaxSize.
+func WithMaxSize(size int) Option {
+	return func(o *options) {
+		o.maxSize = size
+	}
+}
+
+// WithMaxLevels sets the maximum number of encoded levels. The upper levels and the root are kept. Decode rejects the
+// errors with more levels. Defaults to DefaultMaxLevels.
+func WithMaxLevels(levels int) Option {
+	return func(o *options) {
+		o.maxLevels = levels
+	}
+}
+
+// WithMaxString sets the maximum length in bytes of an encoded string. Decode rejects the errors with longer strings.
+// Defaults to DefaultMaxString.
+func WithMaxString(length int) Option {
+	return func(o *options) {
+		o.maxString = length
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{maxSize: DefaultMaxSize, maxLevels: DefaultMaxLevels, maxString: DefaultMaxString}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxLevels < 1 {
+		o.maxLevels = 1
+	}
+	if o.maxString < 1 {
+		o.maxString = 1
+	}
+	if o.frames < 0 {
+		o.frames = 0
+	}
+	return o
+}
+
+type level struct {
+	entries []entry
+	context string
+	fields  [][2]string
+	frames  []string
+}
+
+type entry struct {
+	tag   byte
+	value string
+	// fields are the fields of an instantiated Template.
+	fields [][2]string
+}
+
+// fielder is implemented by the levels of errorwrap.
+type fielder interface {
+	Fields() errorwrap.Fields
+}
+
+// limits are the limits of a single encoding attempt. framesDropped is set once the frames are dropped to meet the
+// maximum size.
+type limits struct {
+	levels        int
+	items         int
+	maxString     int
+	frames        int
+	framesDropped bool
+}
+
+// Encode encodes err. If err is nil then Encode returns nil. An error that is not an ErrorWrapper is encoded as a root
+// level containing it. If err cannot fit into the maximum size even once truncated, Encode returns ErrorTooLarge.
+func Encode(err error, opts ...Option) ([]byte, error) {
+	if err == nil {
+		return nil, nil
+	}
+	o := newOptions(opts)
+	levels := collectLevels(err, o.frames)
+
+	l := limits{levels: o.maxLevels, items: maxItems, maxString: o.maxString, frames: o.frames}
+	for {
+		data := encodeLevels(levels, l)
+		if len(data) <= o.maxSize {
+			return data, nil
+		}
+		switch {
+		case l.frames > 0:
+			l.frames = 0
+			for _, lv := range levels {
+				l.framesDropped = l.framesDropped || len(lv.frames) > 0
+			}
+		case l.levels > 2 && len(levels) > 2:
+			if l.levels > len(levels) {
+				l.levels = len(levels)
+			}
+			l.levels /= 2
+			if l.levels < 2 {
+				l.levels = 2
+			}
+		case l.maxString > minString:
+			l.maxString /= 2
+		case l.items > 1:
+			l.items /= 2
+		default:
+			return nil, errorwrap.NewErrorWithMessage(
+				fmt.Sprintf("%d bytes exceed the maximum size of %d bytes", len(data), o.maxSize), ErrorTooLarge)
+		}
+	}
+}
+
+// Truncated reports whether data was truncated by Encode to meet the limits.
+func Truncated(data []byte) bool {
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return false
+	}
+	return data[len(magic)+1]&flagTruncated != 0
+}
+
+// collectLevels returns the levels of err from the current level to the root, with up to frames frames each.
+func collectLevels(err error, frames int) []level {
+	ew, ok := err.(errorwrap.ErrorWrapper)
+	if !ok {
+		return []level{{entries: []entry{newEntry(err)}}}
+	}
+
+	var levels []level
+	for ; ew != nil; ew = ew.ParentError() {
+		var lv level
+		for _, curErr := range ew.CurrentError() {
+			lv.entries = append(lv.entries, newEntry(curErr))
+		}
+		lv.context = errorwrap.Redact(ew.ContextMessage())
+
+		// FieldsOf redacts the values, and the fields of the level overwrite the fields of the lower levels.
+		redacted := errorwrap.FieldsOf(ew)
+		var fields errorwrap.Fields
+		if f, ok := ew.(fielder); ok {
+			fields = f.Fields()
+		}
+		for k := range fields {
+			lv.fields = append(lv.fields, [2]string{k, fmt.Sprint(redacted[k])})
+		}
+		sort.Slice(lv.fields, func(i, j int) bool { return lv.fields[i][0] < lv.fields[j][0] })
+
+		for i, f := range ew.StackTrace() {
+			if i >= frames {
+				break
+			}
+			text, _ := f.MarshalText()
+			lv.frames = append(lv.frames, string(text))
+		}
+		levels = append(levels, lv)
+	}
+	return levels
+}
+
+// newEntry encodes err by its code if it is a registered definition, by its code and fields if it is instantiated from
+// a registered Template, otherwise by its message.
+func newEntry(err error) entry {
+	if code := errorwrap.Code(err); code != "" {
+		def, ok := errorwrap.DefinitionByCode(code)
+		switch {
+		case !ok:
+		case def == err:
+			return entry{tag: tagCode, value: code}
+		case isInstance(err, def):
+			e := entry{tag: tagTemplate, value: code}
+			for k, v := range errorwrap.FieldsOf(err) {
+				e.fields = append(e.fields, [2]string{k, fmt.Sprint(v)})
+			}
+			sort.Slice(e.fields, func(i, j int) bool { return e.fields[i][0] < e.fields[j][0] })
+			return e
+		}
+	}
+	return entry{tag: tagMessage, value: err.Error()}
+}
+
+// isInstance checks whether err is instantiated from def, which must be a Template.
+func isInstance(err error, def error) bool {
+	if _, ok := def.(errorwrap.Template); !ok {
+		return false
+	}
+	if _, ok := err.(errorwrap.ErrorWrapper); ok {
+		return false
+	}
+	return errors.Is(err, def)
+}
+
+// encodeLevels encodes levels within l. The frames flag is set if frames are encoded, and the truncated flag is set if
+// anything was left out.
+func encodeLevels(levels []level, l limits) []byte {
+	w := &writer{truncated: l.framesDropped}
+	if len(levels) > l.levels {
+		kept := make([]level, 0, l.levels)
+		kept = append(kept, levels[:l.levels-1]...)
+		levels = append(kept, levels[len(levels)-1])
+		w.truncated = true
+	}
+
+	var flags byte
+	if l.frames > 0 {
+		flags |= flagFrames
+	}
+	w.buf = append(w.buf, magic...)
+	w.buf = append(w.buf, Version, flags)
+
+	w.uvarint(len(levels))
+	for _, lv := range levels {
+		entries := lv.entries
+		if len(entries) > l.items {
+			entries = entries[:l.items]
+			w.truncated = true
+		}
+		w.uvarint(len(entries))
+		for _, e := range entries {
+			w.buf = append(w.buf, e.tag)
+			w.string(e.value, l.maxString)
+			if e.tag == tagTemplate {
+				w.fields(e.fields, l)
+			}
+		}
+
+		w.string(lv.context, l.maxString)
+
+		w.fields(lv.fields, l)
+
+		if l.frames > 0 {
+			frames := lv.frames
+			if len(frames) > l.frames {
+				frames = frames[:l.frames]
+			}
+			w.uvarint(len(frames))
+			for _, f := range frames {
+				w.string(f, l.maxString)
+			}
+		}
+	}
+
+	if w.truncated {
+		w.buf[len(magic)+1] |= flagTruncated
+	}
+	return w.buf
+}
+
+type writer struct {
+	buf       []byte
+	truncated bool
+}
+
+func (w *writer) uvarint(v int) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], uint64(v))
+	w.buf = append(w.buf, scratch[:n]...)
+}
+
+// fields writes up to l.items fields.
+func (w *writer) fields(fields [][2]string, l limits) {
+	if len(fields) > l.items {
+		fields = fields[:l.items]
+		w.truncated = true
+	}
+	w.uvarint(len(fields))
+	for _, f := range fields {
+		w.string(f[0], l.maxString)
+		w.string(f[1], l.maxString)
+	}
+}
+
+// string writes s shortened to max bytes, without splitting a UTF-8 sequence.
+func (w *writer) string(s string, max int) {
+	if len(s) > max {
+		cut := max
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+		w.truncated = true
+	}
+	w.uvarint(len(s))
+	w.buf = append(w.buf, s...)
+}
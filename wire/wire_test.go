@@ -0,0 +1,275 @@
+package wire
+
+import (
+	"errors"
+	"github.com/anantadwi13/errorwrap"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var (
+	ErrorUserNotFound = errorwrap.New("error user not found", errorwrap.WithCode("USER_NOT_FOUND"))
+	ErrorDomain       = errorwrap.New("error domain layer", errorwrap.WithCode("DOMAIN"))
+	ErrorUseCase      = errorwrap.New("error use case layer", errorwrap.WithCode("USE_CASE"))
+	ErrorUnregistered = errorwrap.New("error unregistered", errorwrap.WithCode("UNREGISTERED"))
+	ErrorUserMissing  = errorwrap.NewTemplate("user {id} not found in {region}", errorwrap.WithCode("USER_MISSING"))
+)
+
+func init() {
+	errorwrap.RegisterDefinition(ErrorUserNotFound, ErrorDomain, ErrorUseCase, ErrorUserMissing)
+}
+
+func deepError(levels int) error {
+	err := errorwrap.NewError(ErrorUserNotFound)
+	for i := 1; i < levels; i++ {
+		err = errorwrap.Wrap(err, ErrorDomain)
+	}
+	return err
+}
+
+func TestEncodeDecode(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantLevels  [][]string
+		wantIs      []error
+		wantContext string
+		wantFields  errorwrap.Fields
+	}{
+		{
+			name:       "root level",
+			args:       args{err: errorwrap.NewError(ErrorUserNotFound, errors.New("standard error"))},
+			wantLevels: [][]string{{"error user not found", "standard error"}},
+			wantIs:     []error{ErrorUserNotFound},
+		},
+		{
+			name: "wrapper levels",
+			args: args{err: errorwrap.WrapWithMessage(
+				errorwrap.AppendFields(errorwrap.NewError(ErrorUserNotFound), errorwrap.Fields{"user_id": 42}),
+				"find user", ErrorDomain, ErrorUseCase)},
+			wantLevels:  [][]string{{"error domain layer", "error use case layer"}, {"error user not found"}},
+			wantIs:      []error{ErrorUserNotFound, ErrorDomain, ErrorUseCase},
+			wantContext: "find user",
+			wantFields:  errorwrap.Fields{"user_id": "42"},
+		},
+		{
+			name:       "unregistered definition",
+			args:       args{err: errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorUnregistered)},
+			wantLevels: [][]string{{"error unregistered"}, {"error user not found"}},
+			wantIs:     []error{ErrorUserNotFound},
+		},
+		{
+			name: "template",
+			args: args{err: errorwrap.Wrap(
+				errorwrap.NewError(ErrorUserMissing.With(errorwrap.Fields{"id": 42, "region": "eu"})), ErrorDomain)},
+			wantLevels: [][]string{{"error domain layer"}, {"user 42 not found in eu"}},
+			wantIs:     []error{ErrorUserMissing, ErrorDomain},
+			wantFields: errorwrap.Fields{"id": "42", "region": "eu"},
+		},
+		{
+			name:       "foreign error",
+			args:       args{err: errors.New("standard error")},
+			wantLevels: [][]string{{"standard error"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.args.err)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.False(t, Truncated(data))
+
+			got, err := Decode(data)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantLevels, levelMessages(got))
+			for _, target := range tt.wantIs {
+				assert.True(t, errorwrap.Is(got, target), target)
+			}
+			if ew, ok := got.(errorwrap.ErrorWrapper); ok {
+				assert.Equal(t, tt.wantContext, ew.ContextMessage())
+			}
+			if tt.wantFields != nil {
+				assert.Equal(t, tt.wantFields, errorwrap.FieldsOf(got))
+			}
+		})
+	}
+}
+
+func TestEncodeNil(t *testing.T) {
+	data, err := Encode(nil)
+	assert.Nil(t, data)
+	assert.NoError(t, err)
+}
+
+func TestEncodeCodes(t *testing.T) {
+	data, err := Encode(errorwrap.NewError(ErrorUserNotFound))
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(data), "USER_NOT_FOUND")
+		assert.NotContains(t, string(data), "error user not found")
+	}
+}
+
+func TestEncodeRedaction(t *testing.T) {
+	errorwrap.RedactFields("wire_password")
+	err := errorwrap.AppendFields(errorwrap.NewErrorWithMessage("user a@b.io", ErrorUserNotFound),
+		errorwrap.Fields{"wire_password": "secret"})
+	err = errorwrap.AppendInto(err, ErrorUserMissing.With(errorwrap.Fields{"id": 1, "wire_password": "template secret"}))
+	errorwrap.RedactPatterns(errorwrap.PatternEmail)
+
+	data, encodeErr := Encode(err)
+	if assert.NoError(t, encodeErr) {
+		assert.NotContains(t, string(data), "secret")
+		assert.NotContains(t, string(data), "a@b.io")
+	}
+}
+
+func TestFrames(t *testing.T) {
+	err := errorwrap.Wrap(errorwrap.NewError(ErrorUserNotFound), ErrorDomain)
+
+	data, encodeErr := Encode(err)
+	assert.NoError(t, encodeErr)
+	got, decodeErr := Decode(data)
+	assert.NoError(t, decodeErr)
+	var frames RemoteFrames
+	assert.False(t, errorwrap.Detail(got, &frames))
+
+	data, encodeErr = Encode(err, WithFrames(2))
+	assert.NoError(t, encodeErr)
+	got, decodeErr = Decode(data)
+	assert.NoError(t, decodeErr)
+	if assert.True(t, errorwrap.Detail(got, &frames)) && assert.Len(t, frames, 2) {
+		assert.Contains(t, frames[0], "wire.TestFrames")
+		assert.Contains(t, frames[0], "wire_test.go:")
+	}
+}
+
+func TestTruncation(t *testing.T) {
+	type args struct {
+		err  error
+		opts []Option
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantTruncated bool
+		wantLevels    int
+		wantContext   string
+		wantErr       error
+	}{
+		{
+			name:       "within limits",
+			args:       args{err: deepError(4), opts: []Option{WithFrames(4)}},
+			wantLevels: 4,
+		},
+		{
+			name:          "max levels keeps upper levels and root",
+			args:          args{err: deepError(10), opts: []Option{WithMaxLevels(3)}},
+			wantTruncated: true,
+			wantLevels:    3,
+		},
+		{
+			name: "long strings",
+			args: args{
+				err:  errorwrap.NewErrorWithMessage(strings.Repeat("é", 100), ErrorUserNotFound),
+				opts: []Option{WithMaxString(25)},
+			},
+			wantTruncated: true,
+			wantLevels:    1,
+			wantContext:   strings.Repeat("é", 12),
+		},
+		{
+			name:          "max size drops frames",
+			args:          args{err: deepError(1), opts: []Option{WithFrames(32), WithMaxSize(60)}},
+			wantTruncated: true,
+			wantLevels:    1,
+		},
+		{
+			name:          "max size drops frames then levels",
+			args:          args{err: deepError(40), opts: []Option{WithFrames(32), WithMaxSize(128)}},
+			wantTruncated: true,
+			wantLevels:    8,
+		},
+		{
+			name:    "too large",
+			args:    args{err: deepError(1), opts: []Option{WithMaxSize(8)}},
+			wantErr: ErrorTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.args.err, tt.args.opts...)
+			if tt.wantErr != nil {
+				assert.True(t, errorwrap.Is(err, tt.wantErr))
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantTruncated, Truncated(data))
+
+			got, err := Decode(data, tt.args.opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			levels := levelMessages(got)
+			assert.Len(t, levels, tt.wantLevels)
+			assert.Equal(t, []string{"error user not found"}, levels[len(levels)-1])
+			if tt.wantContext != "" {
+				assert.Equal(t, tt.wantContext, got.(errorwrap.ErrorWrapper).ContextMessage())
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid, _ := Encode(errorwrap.NewError(ErrorUserNotFound))
+	deep, _ := Encode(deepError(3))
+	type args struct {
+		data []byte
+		opts []Option
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{name: "empty", args: args{data: nil}, wantErr: ErrorMalformed},
+		{name: "bad magic", args: args{data: []byte("XX\x01\x00\x00")}, wantErr: ErrorMalformed},
+		{name: "newer version", args: args{data: []byte("EW\x02\x00\x01")}, wantErr: ErrorUnsupportedVersion},
+		{name: "no level", args: args{data: []byte("EW\x01\x00\x00")}, wantErr: ErrorMalformed},
+		{name: "count out of range", args: args{data: []byte("EW\x01\x00\xff\xff\xff\xff\x0f")}, wantErr: ErrorMalformed},
+		{name: "unknown tag", args: args{data: []byte("EW\x01\x00\x01\x01\x09\x00\x00\x00")}, wantErr: ErrorMalformed},
+		{name: "truncated", args: args{data: valid[:len(valid)-1]}, wantErr: ErrorMalformed},
+		{name: "trailing bytes", args: args{data: append(append([]byte{}, valid...), 0)}, wantErr: ErrorMalformed},
+		{name: "too large", args: args{data: valid, opts: []Option{WithMaxSize(4)}}, wantErr: ErrorTooLarge},
+		{name: "too many levels", args: args{data: deep, opts: []Option{WithMaxLevels(2)}}, wantErr: ErrorTooLarge},
+		{name: "too long string", args: args{data: valid, opts: []Option{WithMaxString(4)}}, wantErr: ErrorTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.args.data, tt.args.opts...)
+			assert.Nil(t, got)
+			assert.True(t, errorwrap.Is(err, tt.wantErr), err)
+		})
+	}
+}
+
+// levelMessages returns the messages of the errors of each level of err, from current level to the root.
+func levelMessages(err error) [][]string {
+	var levels [][]string
+	for ew, _ := err.(errorwrap.ErrorWrapper); ew != nil; ew = ew.ParentError() {
+		var messages []string
+		for _, curErr := range ew.CurrentError() {
+			messages = append(messages, curErr.Error())
+		}
+		levels = append(levels, messages)
+	}
+	return levels
+}